@@ -0,0 +1,240 @@
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var scopes = map[string]Scope{
+	"cat":               ScopeSession,
+	"keywds":            ScopeSession,
+	"tool":              ScopeSession,
+	"type":              ScopeSession,
+	"group":             ScopeSession,
+	"ice-lite":          ScopeSession,
+	"identity":          ScopeSession,
+	"msid-semantic":     ScopeSession,
+	"rtpmap":            ScopeMedia,
+	"fmtp":              ScopeMedia,
+	"ptime":             ScopeMedia,
+	"maxptime":          ScopeMedia,
+	"framerate":         ScopeMedia,
+	"quality":           ScopeMedia,
+	"orient":            ScopeMedia,
+	"rtcp":              ScopeMedia,
+	"rtcp-mux":          ScopeMedia,
+	"rtcp-rsize":        ScopeMedia,
+	"rtcp-fb":           ScopeMedia,
+	"mid":               ScopeMedia,
+	"msid":              ScopeMedia,
+	"ssrc":              ScopeMedia,
+	"ssrc-group":        ScopeMedia,
+	"candidate":         ScopeMedia,
+	"remote-candidates": ScopeMedia,
+	"end-of-candidates": ScopeMedia,
+	"bundle-only":       ScopeMedia,
+	"rid":               ScopeMedia,
+	"simulcast":         ScopeMedia,
+	"imageattr":         ScopeMedia,
+	"content":           ScopeMedia,
+	"label":             ScopeMedia,
+	"sctp-port":         ScopeMedia,
+	"max-message-size":  ScopeMedia,
+}
+
+// AttributeScope returns the scope where an attribute is allowed to appear.
+// Unknown attributes are allowed everywhere.
+func AttributeScope(name string) Scope {
+	if s, ok := scopes[name]; ok {
+		return s
+	}
+	return ScopeAny
+}
+
+// WalkAttributes calls fn for each attribute of the session and of each media.
+// The attribute returned by fn replaces the original one and it is removed
+// when fn returns false.
+func (f *File) WalkAttributes(fn func(Scope, Attribute) (Attribute, bool)) {
+	f.Attributes = walkAttributes(ScopeSession, f.Attributes, fn)
+	for i := range f.Medias {
+		f.Medias[i].Attributes = walkAttributes(ScopeMedia, f.Medias[i].Attributes, fn)
+	}
+}
+
+func walkAttributes(scope Scope, attrs []Attribute, fn func(Scope, Attribute) (Attribute, bool)) []Attribute {
+	var arr []Attribute
+	for _, a := range attrs {
+		if a, ok := fn(scope, a); ok {
+			arr = append(arr, a)
+		}
+	}
+	return arr
+}
+
+func indexAttribute(name string, attrs []Attribute) int {
+	for i := range attrs {
+		if attrs[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// AttributesWithPrefix returns the session attributes whose name starts with
+// prefix (eg: "ice-").
+func (f File) AttributesWithPrefix(prefix string) []Attribute {
+	return attributesWithPrefix(f.Attributes, prefix)
+}
+
+// AttributesWithPrefix returns the attributes of m whose name starts with
+// prefix (eg: "rtcp-").
+func (m MediaInfo) AttributesWithPrefix(prefix string) []Attribute {
+	return attributesWithPrefix(m.Attributes, prefix)
+}
+
+func attributesWithPrefix(attrs []Attribute, prefix string) []Attribute {
+	var arr []Attribute
+	for _, a := range attrs {
+		if hasNamePrefix(a, prefix) {
+			arr = append(arr, a)
+		}
+	}
+	return arr
+}
+
+func hasNamePrefix(a Attribute, prefix string) bool {
+	return strings.HasPrefix(a.Name, prefix)
+}
+
+// IntAttribute returns the value of the session attribute name as an integer.
+func (f File) IntAttribute(name string) (int64, error) {
+	return intAttribute(f.Attributes, name)
+}
+
+// IntAttribute returns the value of the attribute name of m as an integer (eg:
+// ptime, maxptime, sctp-port). The whole value has to be a number: an error is
+// returned for values like 3d.
+func (m MediaInfo) IntAttribute(name string) (int64, error) {
+	return intAttribute(m.Attributes, name)
+}
+
+func intAttribute(attrs []Attribute, name string) (int64, error) {
+	a, ok := findAttributes(name, attrs)
+	if !ok {
+		return 0, fmt.Errorf("%s not set", name)
+	}
+	n, err := strconv.ParseInt(trimValue(a.Value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: not a number (%s)", ErrSyntax, name, a.Value)
+	}
+	return n, nil
+}
+
+// FloatAttribute returns the value of the session attribute name as a float.
+func (f File) FloatAttribute(name string) (float64, error) {
+	return floatAttribute(f.Attributes, name)
+}
+
+// FloatAttribute returns the value of the attribute name of m as a float (eg:
+// framerate, quality or vendor attributes like X-predecbufsize).
+func (m MediaInfo) FloatAttribute(name string) (float64, error) {
+	return floatAttribute(m.Attributes, name)
+}
+
+func floatAttribute(attrs []Attribute, name string) (float64, error) {
+	a, ok := findAttributes(name, attrs)
+	if !ok {
+		return 0, fmt.Errorf("%s not set", name)
+	}
+	n, err := strconv.ParseFloat(trimValue(a.Value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: not a number (%s)", ErrSyntax, name, a.Value)
+	}
+	return n, nil
+}
+
+// VendorAttributes returns the session attributes prefixed by "X-".
+func (f File) VendorAttributes() []Attribute {
+	return attributesWithPrefix(f.Attributes, "X-")
+}
+
+// VendorAttributes returns the attributes of m prefixed by "X-". Vendor and
+// 3GPP attributes do not have dedicated accessors: their values are read with
+// IntAttribute and FloatAttribute or looked up in Attributes like any other
+// attribute.
+func (m MediaInfo) VendorAttributes() []Attribute {
+	return attributesWithPrefix(m.Attributes, "X-")
+}
+
+// trimValue removes the trailing whitespace left by some senders (eg:
+// a=ptime:20 followed by a space) before a value is interpreted. The
+// attributes themselves are kept as received so Dump writes them unchanged.
+func trimValue(str string) string {
+	return strings.TrimRight(str, " \t")
+}
+
+// hasFlag is the single place where flag attributes (attributes without value
+// like rtcp-mux or ice-lite) are looked up: a flag is set when the attribute
+// is present without a value.
+func hasFlag(attrs []Attribute, name string) bool {
+	for _, a := range attrs {
+		if trimValue(a.Name) == name && trimValue(a.Value) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFlag reports whether the flag attribute name is set at the session level.
+func (f File) HasFlag(name string) bool {
+	return hasFlag(f.Attributes, name)
+}
+
+// HasFlag reports whether the flag attribute name is set on m.
+func (m MediaInfo) HasFlag(name string) bool {
+	return hasFlag(m.Attributes, name)
+}
+
+func setFlag(attrs []Attribute, name string, on bool) []Attribute {
+	if !on {
+		attrs, _ = removeAttributes(attrs, name)
+		return attrs
+	}
+	if _, ok := findAttributes(name, attrs); ok {
+		return attrs
+	}
+	return append(attrs, Attribute{Name: name})
+}
+
+func removeAttributes(attrs []Attribute, name string) ([]Attribute, int) {
+	var (
+		arr []Attribute
+		n   int
+	)
+	for _, a := range attrs {
+		if a.Name == name {
+			n++
+			continue
+		}
+		arr = append(arr, a)
+	}
+	return arr, n
+}
+
+// RemoveAttribute removes all the session attributes with the given name and
+// returns the number of attributes removed. Attributes of the medias are left
+// untouched.
+func (f *File) RemoveAttribute(name string) int {
+	var n int
+	f.Attributes, n = removeAttributes(f.Attributes, name)
+	return n
+}
+
+// RemoveAttribute removes all the attributes with the given name and returns
+// the number of attributes removed.
+func (m *MediaInfo) RemoveAttribute(name string) int {
+	var n int
+	m.Attributes, n = removeAttributes(m.Attributes, name)
+	return n
+}
@@ -0,0 +1,213 @@
+package sdp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRemoveAttribute(t *testing.T) {
+	f := MustParse(offerHead +
+		"a=tool:x\r\n" +
+		"a=ssrc:1 cname:a\r\n" +
+		"m=audio 5000 RTP/AVP 0\r\n" +
+		"a=ssrc:1 cname:a\r\n" +
+		"a=ptime:20\r\n" +
+		"a=ssrc:1 msid:s t\r\n" +
+		"a=ssrc:2 cname:b\r\n")
+	if n := f.Medias[0].RemoveAttribute("ssrc"); n != 3 {
+		t.Errorf("media: want 3 attributes removed, got %d", n)
+	}
+	if as := f.Medias[0].Attributes; len(as) != 1 || as[0].Name != "ptime" {
+		t.Errorf("media: unexpected attributes left: %v", as)
+	}
+	if n := f.Medias[0].RemoveAttribute("ssrc"); n != 0 {
+		t.Errorf("media: want 0 attributes removed, got %d", n)
+	}
+	if n := f.RemoveAttribute("ssrc"); n != 1 {
+		t.Errorf("session: want 1 attribute removed, got %d", n)
+	}
+	if len(f.Attributes) != 1 || len(f.Medias[0].Attributes) != 1 {
+		t.Errorf("session: unexpected attributes left: %v", f.Attributes)
+	}
+	if n := f.RemoveAttribute("ptime"); n != 0 || len(f.Medias[0].Attributes) != 1 {
+		t.Errorf("session: media attributes removed")
+	}
+}
+
+func TestHasFlag(t *testing.T) {
+	f := MustParse(offerHead +
+		"a=ice-lite\r\n" +
+		"a=extmap-allow-mixed\r\n" +
+		"m=audio 5000 RTP/AVP 0\r\n" +
+		"a=rtcp-mux\r\n" +
+		"a=bundle-only\r\n" +
+		"a=end-of-candidates\r\n" +
+		"a=rtcp-rsize:\r\n" +
+		"a=recvonly:1\r\n" +
+		"m=audio 5002 RTP/AVP 0\r\n")
+	if !f.ICELite() || !f.ExtMapAllowMixed() {
+		t.Errorf("session flags not set")
+	}
+	m := f.Medias[0]
+	if !m.RTCPMux() || !m.BundleOnly() || !m.EndOfCandidates() {
+		t.Errorf("media flags not set")
+	}
+	for _, name := range []string{"rtcp-mux", "bundle-only", "end-of-candidates", "rtcp-rsize"} {
+		if !m.HasFlag(name) {
+			t.Errorf("%s: flag not set", name)
+		}
+		if f.HasFlag(name) {
+			t.Errorf("%s: media flag set on session", name)
+		}
+	}
+	if m.HasFlag("recvonly") {
+		t.Errorf("attribute with value used as flag")
+	}
+	if m.HasFlag("ice-lite") {
+		t.Errorf("session flag set on media")
+	}
+	m = f.Medias[1]
+	if m.RTCPMux() || m.BundleOnly() || m.EndOfCandidates() {
+		t.Errorf("flags set on media without attributes")
+	}
+}
+
+func TestAttributesWithPrefix(t *testing.T) {
+	f := MustParse(offerHead +
+		"a=rtcp-xr:rcvr-rtt=all\r\n" +
+		"a=tool:x\r\n" +
+		"m=video 5000 RTP/AVPF 96\r\n" +
+		"a=rtcp:5001\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n" +
+		"a=rtcp-fb:96 nack\r\n" +
+		"a=rtcp-mux\r\n" +
+		"a=rtcp-fb:96 nack pli\r\n" +
+		"a=rtcp-rsize\r\n")
+	as := f.Medias[0].AttributesWithPrefix("rtcp-")
+	want := []string{"rtcp-fb", "rtcp-mux", "rtcp-fb", "rtcp-rsize"}
+	if len(as) != len(want) {
+		t.Fatalf("want %d attributes, got %v", len(want), as)
+	}
+	for i := range want {
+		if as[i].Name != want[i] {
+			t.Errorf("attribute #%d: want %s, got %s", i, want[i], as[i].Name)
+		}
+	}
+	if as[3].Value != "" || as[2].Value != "96 nack pli" {
+		t.Errorf("values not kept: %v", as)
+	}
+	if as := f.AttributesWithPrefix("rtcp-"); len(as) != 1 || as[0].Name != "rtcp-xr" {
+		t.Errorf("session: unexpected attributes %v", as)
+	}
+	if as := f.Medias[0].AttributesWithPrefix("ice-"); len(as) != 0 {
+		t.Errorf("unexpected attributes %v", as)
+	}
+}
+
+func TestIntAttribute(t *testing.T) {
+	f := MustParse(offerHead +
+		"a=x-count:12\r\n" +
+		"m=video 5000 RTP/AVP 96\r\n" +
+		"a=label:3d\r\n" +
+		"a=ptime:20\r\n" +
+		"a=maxptime:\r\n" +
+		"a=framerate:29.97\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n")
+	m := f.Medias[0]
+	data := []struct {
+		Name  string
+		Value int64
+		Err   error
+	}{
+		{Name: "ptime", Value: 20},
+		{Name: "label", Err: ErrSyntax},
+		{Name: "maxptime", Err: ErrSyntax},
+		{Name: "framerate", Err: ErrSyntax},
+	}
+	for _, d := range data {
+		n, err := m.IntAttribute(d.Name)
+		if d.Err != nil {
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%s: expected %s, got %v", d.Name, d.Err, err)
+			}
+			if n != 0 {
+				t.Errorf("%s: want 0, got %d", d.Name, n)
+			}
+			continue
+		}
+		if err != nil || n != d.Value {
+			t.Errorf("%s: want %d, got %d (%v)", d.Name, d.Value, n, err)
+		}
+	}
+	if _, err := m.IntAttribute("sctp-port"); err == nil {
+		t.Errorf("expected error for a missing attribute")
+	}
+	if n, err := f.IntAttribute("x-count"); err != nil || n != 12 {
+		t.Errorf("session: want 12, got %d (%v)", n, err)
+	}
+	if v, err := m.FloatAttribute("framerate"); err != nil || v != 29.97 {
+		t.Errorf("framerate: want 29.97, got %f (%v)", v, err)
+	}
+}
+
+func TestVendorAttributes(t *testing.T) {
+	f := MustParse(offerHead +
+		"a=X-nat:0\r\n" +
+		"a=tool:x\r\n" +
+		"m=video 5000 RTP/AVP 96\r\n" +
+		"a=rtpmap:96 H264/90000\r\n" +
+		"a=X-predecbufsize:78000\r\n" +
+		"a=framesize:96 176-144\r\n" +
+		"a=X-initpredecbufperiod:4800\r\n" +
+		"a=X-framerate:29.97\r\n" +
+		"a=3gpp-adaptation-support:1\r\n")
+	m := f.Medias[0]
+	as := m.VendorAttributes()
+	want := []string{"X-predecbufsize", "X-initpredecbufperiod", "X-framerate"}
+	if len(as) != len(want) {
+		t.Fatalf("want %d attributes, got %v", len(want), as)
+	}
+	for i := range want {
+		if as[i].Name != want[i] {
+			t.Errorf("attribute #%d: want %s, got %s", i, want[i], as[i].Name)
+		}
+	}
+	if n, err := m.IntAttribute("X-predecbufsize"); err != nil || n != 78000 {
+		t.Errorf("X-predecbufsize: want 78000, got %d (%v)", n, err)
+	}
+	if v, err := m.FloatAttribute("X-framerate"); err != nil || v != 29.97 {
+		t.Errorf("X-framerate: want 29.97, got %f (%v)", v, err)
+	}
+	if n, err := m.IntAttribute("3gpp-adaptation-support"); err != nil || n != 1 {
+		t.Errorf("3gpp-adaptation-support: want 1, got %d (%v)", n, err)
+	}
+	if as := f.VendorAttributes(); len(as) != 1 || as[0].Name != "X-nat" {
+		t.Errorf("session: unexpected attributes %v", as)
+	}
+}
+
+func TestTrailingSpaces(t *testing.T) {
+	const media = "m=audio 5000 RTP/AVP 0\r\n" +
+		"a=ptime:20 \r\n" +
+		"a=maxptime:40\t\r\n" +
+		"a=rtcp-mux \r\n" +
+		"a=sendonly \r\n"
+	f := MustParse(offerHead + media)
+	m := f.Medias[0]
+	if n, err := m.IntAttribute("ptime"); err != nil || n != 20 {
+		t.Errorf("ptime: want 20, got %d (%v)", n, err)
+	}
+	if n, err := m.IntAttribute("maxptime"); err != nil || n != 40 {
+		t.Errorf("maxptime: want 40, got %d (%v)", n, err)
+	}
+	if !m.RTCPMux() || !m.HasFlag("rtcp-mux") {
+		t.Errorf("rtcp-mux: flag with trailing space not set")
+	}
+	if d, ok := m.Direction(); !ok || d != SendOnly {
+		t.Errorf("direction: want %s, got %s (%t)", SendOnly, d, ok)
+	}
+	if str := f.Dump(); !strings.HasSuffix(str, media) {
+		t.Errorf("attributes not written as received: %q", str)
+	}
+}
@@ -0,0 +1,96 @@
+package sdp
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	FloorClientOnly   = "c-only"
+	FloorServerOnly   = "s-only"
+	FloorClientServer = "c-s"
+)
+
+// FloorCtrl returns the floor control roles (c-only, s-only, c-s) that the
+// endpoint of a BFCP media can take (RFC 8856).
+func (m MediaInfo) FloorCtrl() ([]string, bool) {
+	a, ok := findAttributes("floorctrl", m.Attributes)
+	if !ok {
+		return nil, false
+	}
+	return strings.Fields(a.Value), true
+}
+
+// ConfID returns the identifier of the conference of a BFCP media.
+func (m MediaInfo) ConfID() (string, bool) {
+	a, ok := findAttributes("confid", m.Attributes)
+	return a.Value, ok
+}
+
+// UserID returns the identifier of the user of a BFCP media.
+func (m MediaInfo) UserID() (string, bool) {
+	a, ok := findAttributes("userid", m.Attributes)
+	return a.Value, ok
+}
+
+// FloorID associates a floor to the label of the medias it controls.
+type FloorID struct {
+	ID      string
+	Streams []string
+}
+
+func (f FloorID) String() string {
+	if len(f.Streams) == 0 {
+		return f.ID
+	}
+	return f.ID + " mstrm:" + strings.Join(f.Streams, " ")
+}
+
+func (m MediaInfo) FloorIDs() ([]FloorID, error) {
+	var arr []FloorID
+	for _, a := range m.Attributes {
+		if a.Name != "floorid" {
+			continue
+		}
+		f, err := parseFloorID(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, f)
+	}
+	return arr, nil
+}
+
+// floorid:<floor-id> [mstrm:<token> *(SP <token>)]
+//
+// m-stream is accepted as an alias of mstrm (RFC 4583).
+func parseFloorID(str string) (FloorID, error) {
+	var (
+		f     FloorID
+		parts = strings.Fields(str)
+	)
+	if len(parts) == 0 {
+		return f, fmt.Errorf("%w: floorid (%s)", ErrSyntax, str)
+	}
+	f.ID = parts[0]
+	if len(parts) == 1 {
+		return f, nil
+	}
+	var label string
+	switch {
+	case strings.HasPrefix(parts[1], "mstrm:"):
+		label = parts[1][6:]
+	case strings.HasPrefix(parts[1], "m-stream:"):
+		label = parts[1][9:]
+	default:
+		return f, fmt.Errorf("%w: floorid (%s)", ErrSyntax, str)
+	}
+	if label != "" {
+		f.Streams = append(f.Streams, label)
+	}
+	f.Streams = append(f.Streams, parts[2:]...)
+	if len(f.Streams) == 0 {
+		return f, fmt.Errorf("%w: floorid without stream (%s)", ErrSyntax, str)
+	}
+	return f, nil
+}
@@ -0,0 +1,69 @@
+package sdp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBFCP(t *testing.T) {
+	const media = "m=application 50000 TCP/TLS/BFCP *\r\n" +
+		"a=setup:passive\r\n" +
+		"a=connection:new\r\n" +
+		"a=fingerprint:sha-256 AB:CD:EF\r\n" +
+		"a=floorctrl:c-only s-only\r\n" +
+		"a=confid:4321\r\n" +
+		"a=userid:1234\r\n" +
+		"a=floorid:1 mstrm:10\r\n" +
+		"a=floorid:2 m-stream:11 12\r\n" +
+		"a=floorid:3\r\n" +
+		"m=video 50002 RTP/AVP 96\r\n" +
+		"a=rtpmap:96 H264/90000\r\n" +
+		"a=label:10\r\n"
+	f := MustParse(offerHead + media)
+	m := f.Medias[0]
+	if m.Proto != "TCP/TLS/BFCP" {
+		t.Fatalf("unexpected proto %s", m.Proto)
+	}
+	roles, ok := m.FloorCtrl()
+	if !ok || strings.Join(roles, " ") != FloorClientOnly+" "+FloorServerOnly {
+		t.Errorf("floorctrl: got %v (%t)", roles, ok)
+	}
+	if id, ok := m.ConfID(); !ok || id != "4321" {
+		t.Errorf("confid: got %s (%t)", id, ok)
+	}
+	if id, ok := m.UserID(); !ok || id != "1234" {
+		t.Errorf("userid: got %s (%t)", id, ok)
+	}
+	fs, err := m.FloorIDs()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []string{"1 mstrm:10", "2 mstrm:11 12", "3"}
+	if len(fs) != len(want) {
+		t.Fatalf("want %d floors, got %v", len(want), fs)
+	}
+	for i := range want {
+		if got := fs[i].String(); got != want[i] {
+			t.Errorf("floor #%d: want %s, got %s", i, want[i], got)
+		}
+	}
+	if str := f.Dump(); !strings.HasSuffix(str, media) {
+		t.Errorf("BFCP media not written back: %q", str)
+	}
+
+	m = f.Medias[1]
+	if _, ok := m.FloorCtrl(); ok {
+		t.Errorf("floorctrl set on video media")
+	}
+	if _, ok := m.ConfID(); ok {
+		t.Errorf("confid set on video media")
+	}
+
+	for _, str := range []string{"1 label:10", "1 mstrm:", ""} {
+		m := MediaInfo{Attributes: []Attribute{{Name: "floorid", Value: str}}}
+		if _, err := m.FloorIDs(); !errors.Is(err, ErrSyntax) {
+			t.Errorf("%q: expected %s, got %v", str, ErrSyntax, err)
+		}
+	}
+}
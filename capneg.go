@@ -0,0 +1,118 @@
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TransportCap is one transport protocol advertised by a tcap attribute
+// (RFC 5939). Numbers are assigned in sequence from the first number of the
+// attribute.
+type TransportCap struct {
+	Number int
+	Proto  string
+}
+
+// PotentialConfig is the value of a pcfg attribute (RFC 5939).
+//
+// Only a subset of the grammar is interpreted: transport configuration lists
+// (t=) are resolved to the capability numbers of each alternative and
+// attribute configuration lists (a=) are split into alternatives of capability
+// references kept verbatim (eg: "[2]" for an optional capability or "-m:1" for
+// a delete request). Any other configuration list (extensions, media
+// capabilities,...) is kept untouched in Extensions.
+type PotentialConfig struct {
+	Number     int
+	Attributes [][]string
+	Transports []int
+	Extensions []Attribute
+}
+
+// TransportCapabilities returns all the transport protocols advertised by the
+// tcap attributes of the media.
+func (m MediaInfo) TransportCapabilities() ([]TransportCap, error) {
+	var arr []TransportCap
+	for _, a := range m.Attributes {
+		if a.Name != "tcap" {
+			continue
+		}
+		cs, err := parseTransportCaps(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, cs...)
+	}
+	return arr, nil
+}
+
+func (m MediaInfo) PotentialConfigs() ([]PotentialConfig, error) {
+	var arr []PotentialConfig
+	for _, a := range m.Attributes {
+		if a.Name != "pcfg" {
+			continue
+		}
+		c, err := parsePotentialConfig(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, c)
+	}
+	return arr, nil
+}
+
+// tcap:<trpr-cap-num> <proto-list>
+func parseTransportCaps(str string) ([]TransportCap, error) {
+	parts := strings.Fields(str)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("%w: tcap (%s)", ErrSyntax, str)
+	}
+	n, err := strconv.Atoi(parts[0])
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("%w: tcap number (%s)", ErrSyntax, parts[0])
+	}
+	var arr []TransportCap
+	for i, p := range parts[1:] {
+		arr = append(arr, TransportCap{Number: n + i, Proto: p})
+	}
+	return arr, nil
+}
+
+// pcfg:<config-number> [<pot-cfg-list>]
+func parsePotentialConfig(str string) (PotentialConfig, error) {
+	var (
+		cfg   PotentialConfig
+		parts = strings.Fields(str)
+		err   error
+	)
+	if len(parts) == 0 {
+		return cfg, fmt.Errorf("%w: pcfg (%s)", ErrSyntax, str)
+	}
+	if cfg.Number, err = strconv.Atoi(parts[0]); err != nil || cfg.Number <= 0 {
+		return cfg, fmt.Errorf("%w: pcfg number (%s)", ErrSyntax, parts[0])
+	}
+	for _, p := range parts[1:] {
+		x := strings.Index(p, "=")
+		if x <= 0 || x == len(p)-1 {
+			return cfg, fmt.Errorf("%w: pcfg list (%s)", ErrSyntax, p)
+		}
+		name, list := p[:x], p[x+1:]
+		switch name {
+		case "t":
+			for _, t := range strings.Split(list, "|") {
+				n, err := strconv.Atoi(t)
+				if err != nil {
+					return cfg, fmt.Errorf("%w: pcfg transport (%s)", ErrSyntax, t)
+				}
+				cfg.Transports = append(cfg.Transports, n)
+			}
+		case "a":
+			for _, alt := range strings.Split(list, "|") {
+				cfg.Attributes = append(cfg.Attributes, strings.Split(alt, ","))
+			}
+		default:
+			cfg.Extensions = append(cfg.Extensions, Attribute{Name: name, Value: list})
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,226 @@
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	ClockNTP      = "ntp"
+	ClockPTP      = "ptp"
+	ClockGPS      = "gps"
+	ClockGalileo  = "gal"
+	ClockGlonass  = "glonass"
+	ClockLocal    = "local"
+	ClockPrivate  = "private"
+	ClockLocalMAC = "localmac"
+)
+
+// RefClock is the value of a ts-refclk attribute (RFC 7273). The supported
+// sources are:
+//
+//	ntp=<server>|/traceable/
+//	ptp=<version>:<gmid>[:<domain>] | ptp=<version>:traceable
+//	gps, gal, glonass, local and private[:traceable]
+//	localmac=<mac address>
+//
+// Any other source is kept as is in Source and Param.
+type RefClock struct {
+	Source    string
+	Param     string
+	Version   string
+	GMID      string
+	Domain    string
+	Traceable bool
+}
+
+func (r RefClock) String() string {
+	var str strings.Builder
+	str.WriteString(r.Source)
+	switch r.Source {
+	case ClockPTP:
+		str.WriteByte('=')
+		str.WriteString(r.Version)
+		str.WriteByte(':')
+		if r.Traceable {
+			str.WriteString("traceable")
+			break
+		}
+		str.WriteString(r.GMID)
+		if r.Domain != "" {
+			str.WriteByte(':')
+			str.WriteString(r.Domain)
+		}
+	case ClockNTP:
+		str.WriteByte('=')
+		if r.Traceable {
+			str.WriteString("/traceable/")
+			break
+		}
+		str.WriteString(r.Param)
+	case ClockPrivate:
+		if r.Traceable {
+			str.WriteString(":traceable")
+		}
+	default:
+		if r.Param != "" {
+			str.WriteByte('=')
+			str.WriteString(r.Param)
+		}
+	}
+	return str.String()
+}
+
+func (m MediaInfo) RefClock() (RefClock, error) {
+	a, ok := findAttributes("ts-refclk", m.Attributes)
+	if !ok {
+		return RefClock{}, fmt.Errorf("ts-refclk not set")
+	}
+	return parseRefClock(a.Value)
+}
+
+// ts-refclk:<clksrc>
+func parseRefClock(str string) (RefClock, error) {
+	var r RefClock
+	if str == "" {
+		return r, fmt.Errorf("%w: ts-refclk (%s)", ErrSyntax, str)
+	}
+	r.Source, r.Param = str, ""
+	if x := strings.IndexAny(str, "=:"); x > 0 {
+		r.Source, r.Param = str[:x], str[x+1:]
+	}
+	switch r.Source {
+	case ClockPTP:
+		parts := strings.Split(r.Param, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return r, fmt.Errorf("%w: ts-refclk ptp (%s)", ErrSyntax, str)
+		}
+		r.Version, r.Param = parts[0], ""
+		if parts[1] == "traceable" && len(parts) == 2 {
+			r.Traceable = true
+			break
+		}
+		r.GMID = parts[1]
+		if len(parts) == 3 {
+			if _, err := strconv.ParseUint(parts[2], 10, 8); err != nil {
+				return r, fmt.Errorf("%w: ts-refclk ptp domain (%s)", ErrSyntax, parts[2])
+			}
+			r.Domain = parts[2]
+		}
+	case ClockNTP:
+		if r.Param == "" {
+			return r, fmt.Errorf("%w: ts-refclk ntp (%s)", ErrSyntax, str)
+		}
+		if r.Param == "/traceable/" {
+			r.Traceable, r.Param = true, ""
+		}
+	case ClockPrivate:
+		if r.Param != "" && r.Param != "traceable" {
+			return r, fmt.Errorf("%w: ts-refclk private (%s)", ErrSyntax, str)
+		}
+		r.Traceable, r.Param = r.Param == "traceable", ""
+	}
+	return r, nil
+}
+
+const (
+	MediaClockDirect   = "direct"
+	MediaClockSender   = "sender"
+	MediaClockIEEE1722 = "IEEE1722"
+)
+
+// MediaClock is the value of a mediaclk attribute (RFC 7273). The supported
+// forms are:
+//
+//	direct[=<offset>] [rate=<numerator>/<denominator>]
+//	sender
+//	IEEE1722=<stream id>
+type MediaClock struct {
+	Mode     string
+	Offset   int64
+	RateNum  int
+	RateDen  int
+	StreamID string
+}
+
+func (c MediaClock) String() string {
+	var str strings.Builder
+	str.WriteString(c.Mode)
+	switch c.Mode {
+	case MediaClockDirect:
+		str.WriteByte('=')
+		str.WriteString(strconv.FormatInt(c.Offset, 10))
+		if c.RateNum > 0 {
+			str.WriteString(" rate=")
+			str.WriteString(strconv.Itoa(c.RateNum))
+			str.WriteByte('/')
+			str.WriteString(strconv.Itoa(c.RateDen))
+		}
+	case MediaClockIEEE1722:
+		str.WriteByte('=')
+		str.WriteString(c.StreamID)
+	}
+	return str.String()
+}
+
+func (m MediaInfo) MediaClock() (MediaClock, error) {
+	a, ok := findAttributes("mediaclk", m.Attributes)
+	if !ok {
+		return MediaClock{}, fmt.Errorf("mediaclk not set")
+	}
+	return parseMediaClock(a.Value)
+}
+
+// mediaclk:[id=<id> ]<mediaclock>
+func parseMediaClock(str string) (MediaClock, error) {
+	var (
+		c     MediaClock
+		parts = strings.Fields(str)
+	)
+	if len(parts) > 0 && strings.HasPrefix(parts[0], "id=") {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		return c, fmt.Errorf("%w: mediaclk (%s)", ErrSyntax, str)
+	}
+	var value string
+	c.Mode = parts[0]
+	if x := strings.Index(parts[0], "="); x > 0 {
+		c.Mode, value = parts[0][:x], parts[0][x+1:]
+	}
+	switch c.Mode {
+	case MediaClockDirect:
+		if value != "" {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return c, fmt.Errorf("%w: mediaclk offset (%s)", ErrSyntax, value)
+			}
+			c.Offset = n
+		}
+		for _, p := range parts[1:] {
+			if !strings.HasPrefix(p, "rate=") {
+				continue
+			}
+			num, den, ok := strings.Cut(p[5:], "/")
+			if !ok {
+				return c, fmt.Errorf("%w: mediaclk rate (%s)", ErrSyntax, p)
+			}
+			var err1, err2 error
+			c.RateNum, err1 = strconv.Atoi(num)
+			c.RateDen, err2 = strconv.Atoi(den)
+			if err1 != nil || err2 != nil || c.RateNum <= 0 || c.RateDen <= 0 {
+				return c, fmt.Errorf("%w: mediaclk rate (%s)", ErrSyntax, p)
+			}
+		}
+	case MediaClockSender:
+	case MediaClockIEEE1722:
+		if value == "" {
+			return c, fmt.Errorf("%w: mediaclk stream id (%s)", ErrSyntax, str)
+		}
+		c.StreamID = value
+	default:
+		return c, fmt.Errorf("%w: unknown media clock %s", ErrInvalid, c.Mode)
+	}
+	return c, nil
+}
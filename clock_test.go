@@ -0,0 +1,129 @@
+package sdp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRefClock(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  RefClock
+		Err   error
+	}{
+		{
+			Input: "ptp=IEEE1588-2008:39-A7-94-FF-FE-07-CB-D0:37",
+			Want:  RefClock{Source: ClockPTP, Version: "IEEE1588-2008", GMID: "39-A7-94-FF-FE-07-CB-D0", Domain: "37"},
+		},
+		{
+			Input: "ptp=IEEE1588-2008:39-A7-94-FF-FE-07-CB-D0",
+			Want:  RefClock{Source: ClockPTP, Version: "IEEE1588-2008", GMID: "39-A7-94-FF-FE-07-CB-D0"},
+		},
+		{
+			Input: "ptp=IEEE1588-2019:traceable",
+			Want:  RefClock{Source: ClockPTP, Version: "IEEE1588-2019", Traceable: true},
+		},
+		{
+			Input: "ntp=203.0.113.10",
+			Want:  RefClock{Source: ClockNTP, Param: "203.0.113.10"},
+		},
+		{
+			Input: "ntp=/traceable/",
+			Want:  RefClock{Source: ClockNTP, Traceable: true},
+		},
+		{
+			Input: "localmac=CA-FE-01-CA-FE-02",
+			Want:  RefClock{Source: ClockLocalMAC, Param: "CA-FE-01-CA-FE-02"},
+		},
+		{
+			Input: "private:traceable",
+			Want:  RefClock{Source: ClockPrivate, Traceable: true},
+		},
+		{Input: "ptp=IEEE1588-2008", Err: ErrSyntax},
+		{Input: "ptp=IEEE1588-2008:39-A7-94-FF-FE-07-CB-D0:domain", Err: ErrSyntax},
+		{Input: "ntp", Err: ErrSyntax},
+	}
+	for _, d := range data {
+		f := MustParse(offerHead + "m=video 5000 RTP/AVP 96\r\na=rtpmap:96 raw/90000\r\na=ts-refclk:" + d.Input + "\r\n")
+		got, err := f.Medias[0].RefClock()
+		if d.Err != nil {
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%s: expected %s, got %v", d.Input, d.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		if got != d.Want {
+			t.Errorf("%s: want %+v, got %+v", d.Input, d.Want, got)
+		}
+		if str := got.String(); str != d.Input {
+			t.Errorf("%s: round trip failed: got %s", d.Input, str)
+		}
+	}
+	f := MustParse(offerHead + "m=video 5000 RTP/AVP 96\r\na=rtpmap:96 raw/90000\r\n")
+	if _, err := f.Medias[0].RefClock(); err == nil {
+		t.Errorf("expected error without ts-refclk")
+	}
+}
+
+func TestMediaClock(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  MediaClock
+		Str   string
+		Err   error
+	}{
+		{
+			Input: "direct=0",
+			Want:  MediaClock{Mode: MediaClockDirect},
+		},
+		{
+			Input: "direct=963214424 rate=1000/1001",
+			Want:  MediaClock{Mode: MediaClockDirect, Offset: 963214424, RateNum: 1000, RateDen: 1001},
+		},
+		{
+			Input: "id=src1 direct=0",
+			Want:  MediaClock{Mode: MediaClockDirect},
+			Str:   "direct=0",
+		},
+		{
+			Input: "sender",
+			Want:  MediaClock{Mode: MediaClockSender},
+		},
+		{
+			Input: "IEEE1722=38-D6-6D-8E-D2-78-13-2F",
+			Want:  MediaClock{Mode: MediaClockIEEE1722, StreamID: "38-D6-6D-8E-D2-78-13-2F"},
+		},
+		{Input: "direct=x", Err: ErrSyntax},
+		{Input: "direct=0 rate=1000", Err: ErrSyntax},
+		{Input: "IEEE1722", Err: ErrSyntax},
+		{Input: "stream=1", Err: ErrInvalid},
+	}
+	for _, d := range data {
+		f := MustParse(offerHead + "m=video 5000 RTP/AVP 96\r\na=rtpmap:96 raw/90000\r\na=mediaclk:" + d.Input + "\r\n")
+		got, err := f.Medias[0].MediaClock()
+		if d.Err != nil {
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%s: expected %s, got %v", d.Input, d.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		if got != d.Want {
+			t.Errorf("%s: want %+v, got %+v", d.Input, d.Want, got)
+		}
+		want := d.Str
+		if want == "" {
+			want = d.Input
+		}
+		if str := got.String(); str != want {
+			t.Errorf("%s: round trip failed: want %s, got %s", d.Input, want, str)
+		}
+	}
+}
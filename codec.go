@@ -0,0 +1,407 @@
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type RTPMap struct {
+	Payload   uint8
+	Encoding  string
+	ClockRate int
+	Channels  int
+}
+
+func (r RTPMap) String() string {
+	var str strings.Builder
+	str.WriteString(strconv.Itoa(int(r.Payload)))
+	str.WriteByte(' ')
+	str.WriteString(r.Encoding)
+	str.WriteByte('/')
+	str.WriteString(strconv.Itoa(r.ClockRate))
+	if r.Channels > 0 {
+		str.WriteByte('/')
+		str.WriteString(strconv.Itoa(r.Channels))
+	}
+	return str.String()
+}
+
+// Same reports whether r and o describe the same codec. The payload number is
+// not taken into account and a missing channel count is equivalent to one.
+func (r RTPMap) Same(o RTPMap) bool {
+	channels := func(n int) int {
+		if n == 0 {
+			return 1
+		}
+		return n
+	}
+	return strings.EqualFold(r.Encoding, o.Encoding) &&
+		r.ClockRate == o.ClockRate &&
+		channels(r.Channels) == channels(o.Channels)
+}
+
+var statics = map[uint8]RTPMap{
+	0:  {Payload: 0, Encoding: "PCMU", ClockRate: 8000},
+	3:  {Payload: 3, Encoding: "GSM", ClockRate: 8000},
+	4:  {Payload: 4, Encoding: "G723", ClockRate: 8000},
+	5:  {Payload: 5, Encoding: "DVI4", ClockRate: 8000},
+	6:  {Payload: 6, Encoding: "DVI4", ClockRate: 16000},
+	7:  {Payload: 7, Encoding: "LPC", ClockRate: 8000},
+	8:  {Payload: 8, Encoding: "PCMA", ClockRate: 8000},
+	9:  {Payload: 9, Encoding: "G722", ClockRate: 8000},
+	10: {Payload: 10, Encoding: "L16", ClockRate: 44100, Channels: 2},
+	11: {Payload: 11, Encoding: "L16", ClockRate: 44100},
+	12: {Payload: 12, Encoding: "QCELP", ClockRate: 8000},
+	13: {Payload: 13, Encoding: "CN", ClockRate: 8000},
+	14: {Payload: 14, Encoding: "MPA", ClockRate: 90000},
+	15: {Payload: 15, Encoding: "G728", ClockRate: 8000},
+	16: {Payload: 16, Encoding: "DVI4", ClockRate: 11025},
+	17: {Payload: 17, Encoding: "DVI4", ClockRate: 22050},
+	18: {Payload: 18, Encoding: "G729", ClockRate: 8000},
+	25: {Payload: 25, Encoding: "CelB", ClockRate: 90000},
+	26: {Payload: 26, Encoding: "JPEG", ClockRate: 90000},
+	28: {Payload: 28, Encoding: "nv", ClockRate: 90000},
+	31: {Payload: 31, Encoding: "H261", ClockRate: 90000},
+	32: {Payload: 32, Encoding: "MPV", ClockRate: 90000},
+	33: {Payload: 33, Encoding: "MP2T", ClockRate: 90000},
+	34: {Payload: 34, Encoding: "H263", ClockRate: 90000},
+}
+
+func (m MediaInfo) RTPMaps() ([]RTPMap, error) {
+	var arr []RTPMap
+	for _, a := range m.Attributes {
+		if a.Name != "rtpmap" {
+			continue
+		}
+		r, err := parseRTPMap(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, r)
+	}
+	return arr, nil
+}
+
+// Formats returns the codec of each payload of the format list in the order
+// they appear. Static payloads without rtpmap are described with the values
+// of RFC 3551. Formats that are neither mapped nor static are skipped.
+func (m MediaInfo) Formats() ([]RTPMap, error) {
+	maps, err := m.RTPMaps()
+	if err != nil {
+		return nil, err
+	}
+	var arr []RTPMap
+	for _, f := range m.Attrs {
+		n, err := strconv.ParseUint(f, 10, 8)
+		if err != nil {
+			continue
+		}
+		p := uint8(n)
+		if r, ok := findRTPMap(p, maps); ok {
+			arr = append(arr, r)
+		} else if r, ok := statics[p]; ok {
+			arr = append(arr, r)
+		}
+	}
+	return arr, nil
+}
+
+// Codec returns the first codec of the format list of m (see Formats) whose
+// encoding name is name, without regard to case.
+func (m MediaInfo) Codec(name string) (RTPMap, bool, error) {
+	fs, err := m.Formats()
+	if err != nil {
+		return RTPMap{}, false, err
+	}
+	for _, r := range fs {
+		if strings.EqualFold(r.Encoding, name) {
+			return r, true, nil
+		}
+	}
+	return RTPMap{}, false, nil
+}
+
+func (m MediaInfo) HasCodec(name string) bool {
+	_, ok, _ := m.Codec(name)
+	return ok
+}
+
+// CodecLocation identifies a payload of a media of a session.
+type CodecLocation struct {
+	MediaIndex int
+	Payload    uint8
+	RTPMap     RTPMap
+}
+
+// FindCodec returns every payload of the medias of f whose encoding name is
+// name, without regard to case. As for Codec, the formats of a media are given
+// by Formats and medias with invalid rtpmap attributes are skipped.
+func (f File) FindCodec(name string) []CodecLocation {
+	var arr []CodecLocation
+	for i, m := range f.Medias {
+		if !m.HasCodec(name) {
+			continue
+		}
+		fs, _ := m.Formats()
+		for _, r := range fs {
+			if !strings.EqualFold(r.Encoding, name) {
+				continue
+			}
+			arr = append(arr, CodecLocation{
+				MediaIndex: i,
+				Payload:    r.Payload,
+				RTPMap:     r,
+			})
+		}
+	}
+	return arr
+}
+
+// CodecsInCommon returns the codecs of a also offered by b, in the order and
+// with the payload numbers of a. Codecs are compared by encoding name (without
+// regard to case), clock rate and channels.
+func CodecsInCommon(a, b MediaInfo) []RTPMap {
+	as, _ := a.Formats()
+	bs, _ := b.Formats()
+
+	var arr []RTPMap
+	for _, r := range as {
+		for _, o := range bs {
+			if r.Same(o) {
+				arr = append(arr, r)
+				break
+			}
+		}
+	}
+	return arr
+}
+
+// PayloadMap returns, for each codec of offer kept in answer, the payload
+// number used by answer for the payload number of offer. Codecs are matched
+// as in CodecsInCommon and each codec of answer is used once. Payloads of
+// offer not found in answer are omitted.
+func PayloadMap(offer, answer MediaInfo) (map[uint8]uint8, error) {
+	offered, err := offer.Formats()
+	if err != nil {
+		return nil, err
+	}
+	answered, err := answer.Formats()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		table = make(map[uint8]uint8)
+		used  = make(map[int]bool)
+	)
+	for _, o := range offered {
+		for i, a := range answered {
+			if !used[i] && o.Same(a) {
+				table[o.Payload] = a.Payload
+				used[i] = true
+				break
+			}
+		}
+	}
+	return table, nil
+}
+
+func findRTPMap(payload uint8, maps []RTPMap) (RTPMap, bool) {
+	for _, r := range maps {
+		if r.Payload == payload {
+			return r, true
+		}
+	}
+	return RTPMap{}, false
+}
+
+// rtpmap:<payload type> <encoding name>/<clock rate>[/<encoding parameters>]
+func parseRTPMap(str string) (RTPMap, error) {
+	var r RTPMap
+	x := strings.Index(str, " ")
+	if x <= 0 {
+		return r, fmt.Errorf("%w: rtpmap (%s)", ErrSyntax, str)
+	}
+	n, err := strconv.ParseUint(str[:x], 10, 8)
+	if err != nil {
+		return r, fmt.Errorf("%w - rtpmap payload: %s", ErrSyntax, err)
+	}
+	if n > 127 {
+		return r, fmt.Errorf("%w: rtpmap payload out of range (%d)", ErrInvalid, n)
+	}
+	r.Payload = uint8(n)
+
+	parts := strings.Split(strings.TrimSpace(str[x+1:]), "/")
+	if len(parts) > 3 || parts[0] == "" {
+		return r, fmt.Errorf("%w: rtpmap (%s)", ErrSyntax, str)
+	}
+	if len(parts) == 1 {
+		return r, fmt.Errorf("%w: rtpmap %d: missing clock rate", ErrSyntax, r.Payload)
+	}
+	r.Encoding = parts[0]
+	if r.ClockRate, err = strconv.Atoi(parts[1]); err != nil {
+		return r, fmt.Errorf("%w - rtpmap clock rate: %s", ErrSyntax, err)
+	}
+	if r.ClockRate <= 0 {
+		return r, fmt.Errorf("%w: rtpmap %d: invalid clock rate %d", ErrInvalid, r.Payload, r.ClockRate)
+	}
+	if len(parts) == 3 {
+		if r.Channels, err = strconv.Atoi(parts[2]); err != nil {
+			return r, fmt.Errorf("%w - rtpmap channels: %s", ErrSyntax, err)
+		}
+		if r.Channels <= 0 {
+			return r, fmt.Errorf("%w: rtpmap %d: invalid channels %d", ErrInvalid, r.Payload, r.Channels)
+		}
+	}
+	return r, nil
+}
+
+// RemapPayload renumbers the payload old to new in the format list and in the
+// rtpmap, fmtp and rtcp-fb attributes referencing it. rtcp-fb attributes using
+// the wildcard are left unchanged.
+func (m *MediaInfo) RemapPayload(old, new uint8) error {
+	var (
+		from = strconv.Itoa(int(old))
+		to   = strconv.Itoa(int(new))
+		pos  = -1
+	)
+	if old == new {
+		return nil
+	}
+	for i, f := range m.Attrs {
+		switch f {
+		case from:
+			pos = i
+		case to:
+			return fmt.Errorf("%w: payload %d already in use", ErrInvalid, new)
+		}
+	}
+	if pos < 0 {
+		return fmt.Errorf("%w: payload %d not in format list", ErrInvalid, old)
+	}
+	for _, a := range m.Attributes {
+		if isPayloadAttribute(a.Name) && payloadOf(a.Value) == to {
+			return fmt.Errorf("%w: payload %d already in use", ErrInvalid, new)
+		}
+	}
+	m.Attrs[pos] = to
+	for i, a := range m.Attributes {
+		if !isPayloadAttribute(a.Name) || payloadOf(a.Value) != from {
+			continue
+		}
+		m.Attributes[i].Value = to + a.Value[len(from):]
+	}
+	return nil
+}
+
+func isPayloadAttribute(name string) bool {
+	return name == "rtpmap" || name == "fmtp" || name == "rtcp-fb"
+}
+
+func payloadOf(value string) string {
+	if x := strings.Index(value, " "); x >= 0 {
+		return value[:x]
+	}
+	return value
+}
+
+// FormatParams is the value of a fmtp attribute. Parameters without value
+// (eg: the events list of telephone-event) are stored with an empty Value.
+type FormatParams struct {
+	Payload uint8
+	Params  []Attribute
+}
+
+func (p FormatParams) Get(name string) (string, bool) {
+	a, ok := findAttributes(name, p.Params)
+	return a.Value, ok
+}
+
+func (p FormatParams) String() string {
+	var str strings.Builder
+	str.WriteString(strconv.Itoa(int(p.Payload)))
+	str.WriteByte(' ')
+	for i, a := range p.Params {
+		if i > 0 {
+			str.WriteByte(';')
+		}
+		str.WriteString(a.Name)
+		if a.Value != "" {
+			str.WriteByte('=')
+			str.WriteString(a.Value)
+		}
+	}
+	return str.String()
+}
+
+// FormatParams returns the parameters of the given payload. When several fmtp
+// attributes target the payload, their parameters are merged and the value of
+// the last occurrence of a parameter wins.
+func (m MediaInfo) FormatParams(payload uint8) (FormatParams, error) {
+	all, err := m.FormatParamsAll(payload)
+	if err != nil {
+		return FormatParams{}, err
+	}
+	if len(all) == 0 {
+		return FormatParams{}, fmt.Errorf("fmtp not set for payload %d", payload)
+	}
+	fp := FormatParams{Payload: payload}
+	for _, p := range all {
+		for _, a := range p.Params {
+			if i := indexAttribute(a.Name, fp.Params); i >= 0 {
+				fp.Params[i].Value = a.Value
+				continue
+			}
+			fp.Params = append(fp.Params, a)
+		}
+	}
+	return fp, nil
+}
+
+// FormatParamsAll returns the parameters of each fmtp attribute targetting
+// the given payload.
+func (m MediaInfo) FormatParamsAll(payload uint8) ([]FormatParams, error) {
+	var arr []FormatParams
+	for _, a := range m.Attributes {
+		if a.Name != "fmtp" {
+			continue
+		}
+		p, err := parseFormatParams(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		if p.Payload == payload {
+			arr = append(arr, p)
+		}
+	}
+	return arr, nil
+}
+
+// fmtp:<format> <format specific parameters>
+func parseFormatParams(str string) (FormatParams, error) {
+	var fp FormatParams
+	x := strings.Index(str, " ")
+	if x <= 0 {
+		return fp, fmt.Errorf("%w: fmtp (%s)", ErrSyntax, str)
+	}
+	n, err := strconv.ParseUint(str[:x], 10, 8)
+	if err != nil {
+		return fp, fmt.Errorf("%w - fmtp payload: %s", ErrSyntax, err)
+	}
+	fp.Payload = uint8(n)
+	for _, p := range strings.Split(str[x+1:], ";") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		var a Attribute
+		if x := strings.Index(p, "="); x < 0 {
+			a.Name = p
+		} else {
+			a.Name = strings.TrimSpace(p[:x])
+			a.Value = strings.TrimSpace(p[x+1:])
+		}
+		fp.Params = append(fp.Params, a)
+	}
+	return fp, nil
+}
@@ -0,0 +1,235 @@
+package sdp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCodecsInCommon(t *testing.T) {
+	a := MustParse(offerHead +
+		"m=audio 5000 RTP/AVP 111 0 8 101\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n" +
+		"a=rtpmap:101 telephone-event/8000\r\n").Medias[0]
+	b := MustParse(localHead +
+		"m=audio 6000 RTP/AVP 96 8 97 98\r\n" +
+		"a=rtpmap:96 telephone-event/8000/1\r\n" +
+		"a=rtpmap:97 OPUS/48000/2\r\n" +
+		"a=rtpmap:98 opus/48000\r\n").Medias[0]
+
+	got := CodecsInCommon(a, b)
+	want := []RTPMap{
+		{Payload: 111, Encoding: "opus", ClockRate: 48000, Channels: 2},
+		{Payload: 8, Encoding: "PCMA", ClockRate: 8000},
+		{Payload: 101, Encoding: "telephone-event", ClockRate: 8000},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%d: want %v, got %v", i, want[i], got[i])
+		}
+	}
+	if got := CodecsInCommon(b, MediaInfo{Attrs: []string{"0"}}); len(got) != 0 {
+		t.Errorf("unexpected codecs in common: %v", got)
+	}
+}
+
+func TestRemapPayload(t *testing.T) {
+	const str = "m=video 5000 RTP/AVP 96 97\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n" +
+		"a=rtcp-fb:96 nack\r\n" +
+		"a=rtcp-fb:* ccm fir\r\n" +
+		"a=rtpmap:97 rtx/90000\r\n" +
+		"a=fmtp:97 apt=96\r\n"
+	m := MustParse(offerHead + str).Medias[0]
+	if err := m.RemapPayload(96, 100); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := "m=video 5000 RTP/AVP 100 97\r\n" +
+		"a=rtpmap:100 VP8/90000\r\n" +
+		"a=rtcp-fb:100 nack\r\n" +
+		"a=rtcp-fb:* ccm fir\r\n" +
+		"a=rtpmap:97 rtx/90000\r\n" +
+		"a=fmtp:97 apt=96\r\n"
+	if got := mediaText(m); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	data := []struct {
+		Old, New uint8
+	}{
+		{Old: 100, New: 97},
+		{Old: 96, New: 101},
+	}
+	for _, d := range data {
+		if err := m.RemapPayload(d.Old, d.New); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%d -> %d: expected %s, got %v", d.Old, d.New, ErrInvalid, err)
+		}
+	}
+	if got := mediaText(m); got != want {
+		t.Errorf("media modified by failed remap: %q", got)
+	}
+}
+
+func TestPayloadMap(t *testing.T) {
+	offer := MustParse(offerHead +
+		"m=audio 5000 RTP/AVP 96 97 98 0\r\n" +
+		"a=rtpmap:96 opus/48000/2\r\n" +
+		"a=rtpmap:97 telephone-event/8000\r\n" +
+		"a=rtpmap:98 telephone-event/48000\r\n").Medias[0]
+	answer := MustParse(localHead +
+		"m=audio 6000 RTP/AVP 111 0 101\r\n" +
+		"a=rtpmap:111 OPUS/48000/2\r\n" +
+		"a=rtpmap:101 telephone-event/8000\r\n").Medias[0]
+	got, err := PayloadMap(offer, answer)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[uint8]uint8{96: 111, 97: 101, 0: 0}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	for o, a := range want {
+		if got[o] != a {
+			t.Errorf("%d: want %d, got %d", o, a, got[o])
+		}
+	}
+}
+
+func TestFormatParamsMerge(t *testing.T) {
+	m := MustParse(offerHead +
+		"m=video 5000 RTP/AVP 96 97\r\n" +
+		"a=rtpmap:96 H264/90000\r\n" +
+		"a=fmtp:96 profile-level-id=42e01f;packetization-mode=0\r\n" +
+		"a=rtpmap:97 VP8/90000\r\n" +
+		"a=fmtp:97 max-fr=30\r\n" +
+		"a=fmtp:96 packetization-mode=1;level-asymmetry-allowed=1\r\n").Medias[0]
+
+	all, err := m.FormatParamsAll(96)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(all) != 2 || len(all[0].Params) != 2 || len(all[1].Params) != 2 {
+		t.Fatalf("want each fmtp line of payload 96, got %v", all)
+	}
+	p, err := m.FormatParams(96)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := "96 profile-level-id=42e01f;packetization-mode=1;level-asymmetry-allowed=1"
+	if got := p.String(); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+	if _, err := m.FormatParams(98); err == nil {
+		t.Errorf("expected error for payload without fmtp")
+	}
+}
+
+func TestCodec(t *testing.T) {
+	m := MustParse(offerHead +
+		"m=audio 5000 RTP/AVP 111 0 101\r\n" +
+		"a=rtpmap:111 OPUS/48000/2\r\n" +
+		"a=rtpmap:101 telephone-event/8000\r\n").Medias[0]
+	for _, name := range []string{"opus", "Opus", "OPUS"} {
+		r, ok, err := m.Codec(name)
+		if err != nil || !ok {
+			t.Errorf("%s: not found (%v)", name, err)
+			continue
+		}
+		if r.Payload != 111 || r.ClockRate != 48000 || r.Channels != 2 {
+			t.Errorf("%s: got %v", name, r)
+		}
+		if !m.HasCodec(name) {
+			t.Errorf("%s: HasCodec: not found", name)
+		}
+	}
+	if r, ok, _ := m.Codec("pcmu"); !ok || r.Payload != 0 {
+		t.Errorf("static payload not found: %v", r)
+	}
+	if _, ok, err := m.Codec("G722"); ok || err != nil || m.HasCodec("G722") {
+		t.Errorf("unexpected codec G722 (%v)", err)
+	}
+	m.Attributes = append(m.Attributes, Attribute{Name: "rtpmap", Value: "x"})
+	if _, _, err := m.Codec("opus"); err == nil || m.HasCodec("opus") {
+		t.Errorf("expected error on invalid rtpmap")
+	}
+}
+
+func TestParseRTPMap(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  RTPMap
+		Err   error
+	}{
+		{Input: "101 telephone-event/8000", Want: RTPMap{Payload: 101, Encoding: "telephone-event", ClockRate: 8000}},
+		{Input: "0 PCMU/8000", Want: RTPMap{Payload: 0, Encoding: "PCMU", ClockRate: 8000}},
+		{Input: "111 opus/48000/2", Want: RTPMap{Payload: 111, Encoding: "opus", ClockRate: 48000, Channels: 2}},
+		{Input: "0 PCMU", Err: ErrSyntax},
+		{Input: "0 PCMU/", Err: ErrSyntax},
+		{Input: "0 PCMU/0", Err: ErrInvalid},
+		{Input: "101 telephone-event/-8000", Err: ErrInvalid},
+		{Input: "111 opus/48000/0", Err: ErrInvalid},
+		{Input: "128 opus/48000", Err: ErrInvalid},
+	}
+	for _, d := range data {
+		pt := strings.Fields(d.Input)[0]
+		f := MustParse(offerHead + "m=audio 5000 RTP/AVP " + pt + "\r\na=rtpmap:" + d.Input + "\r\n")
+		rs, err := f.Medias[0].Formats()
+		if d.Err != nil {
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%s: expected %s, got %v", d.Input, d.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		if len(rs) != 1 || rs[0] != d.Want {
+			t.Errorf("%s: want %+v, got %+v", d.Input, d.Want, rs)
+		}
+		if rs[0].String() != d.Input {
+			t.Errorf("%s: round trip failed: got %s", d.Input, rs[0])
+		}
+	}
+}
+
+func TestFindCodec(t *testing.T) {
+	f := MustParse(offerHead +
+		"m=video 5000 RTP/AVP 96 97 98\r\n" +
+		"a=rtpmap:96 H264/90000\r\n" +
+		"a=rtpmap:97 VP8/90000\r\n" +
+		"a=rtpmap:98 h264/90000\r\n" +
+		"m=audio 5002 RTP/AVP 0 111\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n" +
+		"m=video 5004 RTP/AVP 100\r\n" +
+		"a=rtpmap:100 H264/90000\r\n" +
+		"m=video 5006 RTP/AVP 101\r\n" +
+		"a=rtpmap:101 H264\r\n")
+	ls := f.FindCodec("h264")
+	want := []CodecLocation{
+		{MediaIndex: 0, Payload: 96},
+		{MediaIndex: 0, Payload: 98},
+		{MediaIndex: 2, Payload: 100},
+	}
+	if len(ls) != len(want) {
+		t.Fatalf("want %d locations, got %v", len(want), ls)
+	}
+	for i, w := range want {
+		l := ls[i]
+		if l.MediaIndex != w.MediaIndex || l.Payload != w.Payload || l.RTPMap.Payload != w.Payload {
+			t.Errorf("location #%d: want %d/%d, got %d/%d", i, w.MediaIndex, w.Payload, l.MediaIndex, l.Payload)
+		}
+		if l.RTPMap.ClockRate != 90000 {
+			t.Errorf("location #%d: unexpected rtpmap %s", i, l.RTPMap)
+		}
+	}
+	if ls := f.FindCodec("PCMU"); len(ls) != 1 || ls[0].MediaIndex != 1 || ls[0].Payload != 0 {
+		t.Errorf("static payload not found: %v", ls)
+	}
+	if ls := f.FindCodec("AV1"); len(ls) != 0 {
+		t.Errorf("unexpected locations %v", ls)
+	}
+}
@@ -0,0 +1,58 @@
+package sdp
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ContentSlides  = "slides"
+	ContentSpeaker = "speaker"
+	ContentSign    = "sl"
+	ContentMain    = "main"
+	ContentAlt     = "alt"
+)
+
+// Content returns the value of the content attribute (RFC 4796). The value
+// can be a comma separated list of content types.
+func (m MediaInfo) Content() (string, bool) {
+	a, ok := findAttributes("content", m.Attributes)
+	return a.Value, ok
+}
+
+// HasContent reports whether content is one of the content types of m.
+func (m MediaInfo) HasContent(content string) bool {
+	str, ok := m.Content()
+	if !ok {
+		return false
+	}
+	for _, c := range strings.Split(str, ",") {
+		if c == content {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidContent checks that each content type of value is one defined by RFC
+// 4796.
+func ValidContent(value string) error {
+	for _, c := range strings.Split(value, ",") {
+		switch c {
+		case ContentSlides, ContentSpeaker, ContentSign, ContentMain, ContentAlt:
+		default:
+			return fmt.Errorf("%w: unknown content %q", ErrInvalid, c)
+		}
+	}
+	return nil
+}
+
+func (f File) MediaByContent(content string) []MediaInfo {
+	var arr []MediaInfo
+	for _, m := range f.Medias {
+		if m.HasContent(content) {
+			arr = append(arr, m)
+		}
+	}
+	return arr
+}
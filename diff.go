@@ -0,0 +1,112 @@
+package sdp
+
+import (
+	"strings"
+)
+
+// DiffOptions configures the textual comparison of two sessions.
+//
+// This package has no structural diff of sessions: the comparison is done on
+// the lines written by Dump, which gives the canonical form of a session and
+// already is what is wanted in logs.
+type DiffOptions struct {
+	// Version makes the version of the origin line part of the comparison. It
+	// is ignored by default since it changes with each renegotiation.
+	Version bool
+}
+
+// DiffString returns a textual difference of a and b suitable for logs, as
+// given by the zero DiffOptions: the origin version is not compared. Use
+// DiffOptions.DiffString with Version set to compare it.
+func DiffString(a, b File) string {
+	var opts DiffOptions
+	return opts.DiffString(a, b)
+}
+
+// DiffString compares the canonical form of a and b (as given by Dump) and
+// returns their differences in a format similar to an unified diff: removed
+// lines are prefixed with "-", added lines with "+" and common lines with a
+// space. An empty string is returned when a and b do not differ.
+func (o DiffOptions) DiffString(a, b File) string {
+	if !o.Version {
+		b.Session.Ver = a.Session.Ver
+	}
+	var (
+		as = splitLines(a.Dump())
+		bs = splitLines(b.Dump())
+		ds = diffLines(as, bs)
+	)
+	var (
+		str     strings.Builder
+		changed bool
+	)
+	str.WriteString("--- a\n+++ b\n")
+	for _, d := range ds {
+		if d.op != ' ' {
+			changed = true
+		}
+		str.WriteByte(d.op)
+		str.WriteString(d.line)
+		str.WriteByte('\n')
+	}
+	if !changed {
+		return ""
+	}
+	return str.String()
+}
+
+type diffLine struct {
+	op   byte
+	line string
+}
+
+func diffLines(as, bs []string) []diffLine {
+	lcs := make([][]int, len(as)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bs)+1)
+	}
+	for i := len(as) - 1; i >= 0; i-- {
+		for j := len(bs) - 1; j >= 0; j-- {
+			if as[i] == bs[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var (
+		arr  []diffLine
+		i, j int
+	)
+	for i < len(as) && j < len(bs) {
+		switch {
+		case as[i] == bs[j]:
+			arr = append(arr, diffLine{op: ' ', line: as[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			arr = append(arr, diffLine{op: '-', line: as[i]})
+			i++
+		default:
+			arr = append(arr, diffLine{op: '+', line: bs[j]})
+			j++
+		}
+	}
+	for ; i < len(as); i++ {
+		arr = append(arr, diffLine{op: '-', line: as[i]})
+	}
+	for ; j < len(bs); j++ {
+		arr = append(arr, diffLine{op: '+', line: bs[j]})
+	}
+	return arr
+}
+
+func splitLines(str string) []string {
+	str = strings.TrimRight(str, "\r\n")
+	if str == "" {
+		return nil
+	}
+	return strings.Split(str, "\r\n")
+}
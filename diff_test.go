@@ -0,0 +1,44 @@
+package sdp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffString(t *testing.T) {
+	a := MustParse(offerHead +
+		"m=audio 5000 RTP/AVP 0 8\r\n" +
+		"a=sendrecv\r\n")
+	b := MustParse(offerHead +
+		"m=audio 5002 RTP/AVP 0 8\r\n" +
+		"a=sendonly\r\n")
+	b.BumpVersion()
+
+	data := []struct {
+		Golden  string
+		Options DiffOptions
+	}{
+		{Golden: "diff.golden"},
+		{Golden: "diff_version.golden", Options: DiffOptions{Version: true}},
+	}
+	for _, d := range data {
+		want, err := os.ReadFile(filepath.Join("testdata", d.Golden))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.Options.DiffString(a, b); got != string(want) {
+			t.Errorf("%s: want\n%s\ngot\n%s", d.Golden, want, got)
+		}
+	}
+	if got := DiffString(a, b); got != (DiffOptions{}).DiffString(a, b) {
+		t.Errorf("DiffString does not use the default options")
+	}
+	a.BumpVersion()
+	if got := DiffString(a, a); got != "" {
+		t.Errorf("unexpected difference: %s", got)
+	}
+	if got := DiffString(a, MustParse(a.Dump())); got != "" {
+		t.Errorf("version compared by default: %s", got)
+	}
+}
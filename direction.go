@@ -0,0 +1,91 @@
+package sdp
+
+type Direction string
+
+const (
+	SendRecv Direction = "sendrecv"
+	SendOnly Direction = "sendonly"
+	RecvOnly Direction = "recvonly"
+	Inactive Direction = "inactive"
+)
+
+func (d Direction) CanSend() bool {
+	return d == SendRecv || d == SendOnly
+}
+
+func (d Direction) CanRecv() bool {
+	return d == SendRecv || d == RecvOnly
+}
+
+// Reverse gives the direction seen from the remote side.
+func (d Direction) Reverse() Direction {
+	return makeDirection(d.CanRecv(), d.CanSend())
+}
+
+// Negotiate returns the direction to use in an answer to an offer using
+// direction d when the local side supports the local direction (RFC 3264).
+func (d Direction) Negotiate(local Direction) Direction {
+	return makeDirection(d.CanRecv() && local.CanSend(), d.CanSend() && local.CanRecv())
+}
+
+func makeDirection(send, recv bool) Direction {
+	switch {
+	case send && recv:
+		return SendRecv
+	case send:
+		return SendOnly
+	case recv:
+		return RecvOnly
+	default:
+		return Inactive
+	}
+}
+
+func directionOf(attrs []Attribute) (Direction, bool) {
+	for _, a := range attrs {
+		switch d := Direction(trimValue(a.Name)); d {
+		case SendRecv, SendOnly, RecvOnly, Inactive:
+			return d, true
+		}
+	}
+	return "", false
+}
+
+func setDirection(attrs []Attribute, d Direction) []Attribute {
+	var arr []Attribute
+	for _, a := range attrs {
+		switch Direction(trimValue(a.Name)) {
+		case SendRecv, SendOnly, RecvOnly, Inactive:
+			continue
+		}
+		arr = append(arr, a)
+	}
+	return append(arr, Attribute{Name: string(d)})
+}
+
+// Direction returns the direction set at the session level. Without any
+// direction attribute, the session is sendrecv.
+func (f File) Direction() Direction {
+	if d, ok := directionOf(f.Attributes); ok {
+		return d
+	}
+	return SendRecv
+}
+
+// Direction returns the direction set by the attributes of the media, if any.
+func (m MediaInfo) Direction() (Direction, bool) {
+	return directionOf(m.Attributes)
+}
+
+func (m *MediaInfo) SetDirection(d Direction) {
+	m.Attributes = setDirection(m.Attributes, d)
+}
+
+// EffectiveDirection returns the direction of m, falling back to the direction
+// of the session when m does not have one.
+func (f File) EffectiveDirection(m MediaInfo) Direction {
+	if d, ok := m.Direction(); ok {
+		return d
+	}
+	return f.Direction()
+}
@@ -0,0 +1,85 @@
+package sdp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Fingerprint is the value of a fingerprint attribute (RFC 8122).
+type Fingerprint struct {
+	Hash  string
+	Value string
+}
+
+func (f Fingerprint) String() string {
+	return f.Hash + " " + f.Value
+}
+
+// Fingerprints returns the fingerprints of m or, when m has none, the ones
+// set at the session level.
+func (f File) Fingerprints(m MediaInfo) ([]Fingerprint, error) {
+	arr, err := fingerprintsOf(m.Attributes)
+	if err != nil || len(arr) > 0 {
+		return arr, err
+	}
+	return fingerprintsOf(f.Attributes)
+}
+
+func fingerprintsOf(attrs []Attribute) ([]Fingerprint, error) {
+	var arr []Fingerprint
+	for _, a := range attrs {
+		if a.Name != "fingerprint" {
+			continue
+		}
+		fp, err := parseFingerprint(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, fp)
+	}
+	return arr, nil
+}
+
+// fingerprint:<hash-func> <fingerprint>
+func parseFingerprint(str string) (Fingerprint, error) {
+	var (
+		fp    Fingerprint
+		parts = strings.Fields(str)
+	)
+	if len(parts) != 2 {
+		return fp, fmt.Errorf("%w: fingerprint (%s)", ErrSyntax, str)
+	}
+	fp.Hash = parts[0]
+	fp.Value = parts[1]
+	return fp, nil
+}
+
+// Identity returns the identity assertion of the session (RFC 8827). The
+// value is returned as is: it is a base64 encoded blob that can contain '='
+// and ':'.
+func (f File) Identity() (string, bool) {
+	a, ok := findAttributes("identity", f.Attributes)
+	return a.Value, ok
+}
+
+// TLSID returns the tls-id attribute of the session (RFC 8842).
+func (f File) TLSID() (string, bool) {
+	a, ok := findAttributes("tls-id", f.Attributes)
+	return a.Value, ok
+}
+
+// TLSID returns the tls-id attribute of m. Use File.MediaTLSID to fall back
+// to the value of the session.
+func (m MediaInfo) TLSID() (string, bool) {
+	a, ok := findAttributes("tls-id", m.Attributes)
+	return a.Value, ok
+}
+
+// MediaTLSID returns the identifier of the DTLS association used by m: the
+// tls-id of m when set, the one of the session otherwise.
+func (f File) MediaTLSID(m MediaInfo) (string, bool) {
+	if id, ok := m.TLSID(); ok {
+		return id, ok
+	}
+	return f.TLSID()
+}
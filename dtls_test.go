@@ -0,0 +1,66 @@
+package sdp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIdentity(t *testing.T) {
+	const id = "eyJpZHAiOnsiZG9tYWluIjoiZXhhbXBsZS5vcmcifSwiYXNzZXJ0aW9uIjoiYTpiOmMifQ=="
+	f := MustParse(offerHead + "a=identity:" + id + "\r\nm=audio 9 UDP/TLS/RTP/SAVPF 111\r\n")
+	got, ok := f.Identity()
+	if !ok || got != id {
+		t.Fatalf("want %s, got %s (%t)", id, got, ok)
+	}
+	str := f.Dump()
+	if !strings.Contains(str, "\r\na=identity:"+id+"\r\n") {
+		t.Errorf("identity not written back: %q", str)
+	}
+	g, err := ParseString(str)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, _ := g.Identity(); got != id {
+		t.Errorf("round trip failed: want %s, got %s", id, got)
+	}
+	if _, ok := MustParse(offerHead + "m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n").Identity(); ok {
+		t.Errorf("identity set without attribute")
+	}
+}
+
+func TestTLSID(t *testing.T) {
+	f := MustParse(offerHead +
+		"a=tls-id:abc3de65cddef001be82\r\n" +
+		"m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n" +
+		"m=video 9 UDP/TLS/RTP/SAVPF 96\r\n" +
+		"a=tls-id:dcf7ab2b35479d12e3a2\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n")
+	g, err := ParseString(f.Dump())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if id, ok := g.TLSID(); !ok || id != "abc3de65cddef001be82" {
+		t.Errorf("session: got %s (%t)", id, ok)
+	}
+	data := []struct {
+		Media string
+		Own   bool
+		ID    string
+	}{
+		{Media: "audio", ID: "abc3de65cddef001be82"},
+		{Media: "video", Own: true, ID: "dcf7ab2b35479d12e3a2"},
+	}
+	for i, d := range data {
+		m := g.Medias[i]
+		if _, ok := m.TLSID(); ok != d.Own {
+			t.Errorf("%s: want own tls-id %t", d.Media, d.Own)
+		}
+		if id, ok := g.MediaTLSID(m); !ok || id != d.ID {
+			t.Errorf("%s: want %s, got %s (%t)", d.Media, d.ID, id, ok)
+		}
+	}
+	if _, ok := MustParse(offerHead + "m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n").MediaTLSID(MediaInfo{}); ok {
+		t.Errorf("tls-id set without attribute")
+	}
+}
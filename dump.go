@@ -0,0 +1,106 @@
+package sdp
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// DumpOptions controls how a File is written. The options used by Dump and
+// WriteTo are given by DefaultDumpOptions.
+type DumpOptions struct {
+	// FinalNewline writes the line terminator of the last line. Without it,
+	// the output ends with the last character of the last line.
+	FinalNewline bool
+
+	// OmitOptional skips the optional lines that are not needed to describe
+	// the session: i=, u=, e= and p= lines of the session and i= lines of
+	// the medias.
+	OmitOptional bool
+
+	// ExplicitMediaConn writes a c= line for each media, even for the ones
+	// that inherit the connection information of the session.
+	ExplicitMediaConn bool
+
+	// AttributeFilter, when set, is called for each attribute of the session
+	// and of the medias. The attributes for which it returns false are not
+	// written.
+	AttributeFilter func(scope Scope, a Attribute) bool
+}
+
+func DefaultDumpOptions() DumpOptions {
+	return DumpOptions{
+		FinalNewline: true,
+	}
+}
+
+func (o DumpOptions) Dump(f File) string {
+	var buf bytes.Buffer
+	o.WriteTo(&buf, f)
+	return buf.String()
+}
+
+// WriteTo writes f to w according to o. Like File.WriteTo, nothing is written
+// if f can not be serialized.
+func (o DumpOptions) WriteTo(w io.Writer, f File) (int64, error) {
+	if err := checkText(f); err != nil {
+		return 0, err
+	}
+	f = o.prepare(f)
+	if o.FinalNewline {
+		var (
+			cw = countWriter{Writer: w}
+			ws = bufio.NewWriter(&cw)
+		)
+		writeFile(ws, f, false)
+		err := ws.Flush()
+		return cw.n, err
+	}
+	var (
+		buf bytes.Buffer
+		ws  = bufio.NewWriter(&buf)
+	)
+	writeFile(ws, f, false)
+	ws.Flush()
+	// only the terminator of the last line is removed: a line can not end
+	// with CR or LF (see checkText)
+	n, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\r\n")))
+	return int64(n), err
+}
+
+// prepare returns a copy of f with the changes required by o applied.
+func (o DumpOptions) prepare(f File) File {
+	if !o.OmitOptional && !o.ExplicitMediaConn && o.AttributeFilter == nil {
+		return f
+	}
+	if o.OmitOptional {
+		f.Session.Info = ""
+		f.Session.URI = ""
+		f.Email = nil
+		f.Phone = nil
+	}
+	ms := make([]MediaInfo, len(f.Medias))
+	for i, m := range f.Medias {
+		if o.OmitOptional {
+			m.Info = ""
+		}
+		if o.ExplicitMediaConn && m.ConnInfo.IsZero() {
+			m.ConnInfo = f.ConnInfo
+		}
+		if o.AttributeFilter != nil {
+			m.Attributes = o.filterAttributes(ScopeMedia, m.Attributes)
+		}
+		ms[i] = m
+	}
+	f.Medias = ms
+	if o.AttributeFilter != nil {
+		f.Attributes = o.filterAttributes(ScopeSession, f.Attributes)
+	}
+	return f
+}
+
+func (o DumpOptions) filterAttributes(scope Scope, attrs []Attribute) []Attribute {
+	return walkAttributes(scope, attrs, func(s Scope, a Attribute) (Attribute, bool) {
+		return a, o.AttributeFilter(s, a)
+	})
+}
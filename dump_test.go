@@ -0,0 +1,171 @@
+package sdp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDumpFinalNewline(t *testing.T) {
+	f := MustParse(sample)
+	full := f.Dump()
+	if !strings.HasSuffix(full, "a=rtpmap:99 h263-1998/90000\r\n") {
+		t.Fatalf("unexpected output: %q", full)
+	}
+	if got := DefaultDumpOptions().Dump(f); got != full {
+		t.Errorf("default options: want %q, got %q", full, got)
+	}
+	var (
+		opts DumpOptions
+		buf  bytes.Buffer
+	)
+	n, err := opts.WriteTo(&buf, f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := strings.TrimSuffix(full, "\r\n"); buf.String() != want {
+		t.Errorf("want %q, got %q", want, buf.String())
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("want %d bytes written, got %d", buf.Len(), n)
+	}
+	if !strings.HasSuffix(buf.String(), "h263-1998/90000") {
+		t.Errorf("content of the last line removed: %q", buf.String())
+	}
+
+	// the last line is a media without attributes
+	f.Medias = f.Medias[:1]
+	f.Medias[0].Attributes = nil
+	if got := opts.Dump(f); !strings.HasSuffix(got, "\r\nm=audio 49170 RTP/AVP 0") {
+		t.Errorf("unexpected output: %q", got)
+	}
+}
+
+func TestDumpOmitOptional(t *testing.T) {
+	const str = "v=0\r\n" +
+		"o=jdoe 2890844526 2890842807 IN IP4 10.47.16.5\r\n" +
+		"s=SDP Seminar\r\n" +
+		"i=A Seminar on the session description protocol\r\n" +
+		"u=http://www.example.com/seminars/sdp.pdf\r\n" +
+		"e=j.doe@example.com (Jane Doe)\r\n" +
+		"p=+1 617 555-6011\r\n" +
+		"c=IN IP4 224.2.17.12/127\r\n" +
+		"t=2873397496 2873404696\r\n" +
+		"a=recvonly\r\n" +
+		"m=audio 49170 RTP/AVP 0\r\n" +
+		"i=audio of the seminar\r\n" +
+		"m=video 51372 RTP/AVP 99\r\n" +
+		"a=rtpmap:99 h263-1998/90000\r\n"
+	f := MustParse(str)
+	full := f.Dump()
+	opts := DefaultDumpOptions()
+	opts.OmitOptional = true
+	short := opts.Dump(f)
+	if len(short) >= len(full) {
+		t.Errorf("minified output not shorter: %d >= %d", len(short), len(full))
+	}
+	want := "v=0\r\n" +
+		"o=jdoe 2890844526 2890842807 IN IP4 10.47.16.5\r\n" +
+		"s=SDP Seminar\r\n" +
+		"c=IN IP4 224.2.17.12/127\r\n" +
+		"t=2873397496 2873404696\r\n" +
+		"a=recvonly\r\n" +
+		"m=audio 49170 RTP/AVP 0\r\n" +
+		"m=video 51372 RTP/AVP 99\r\n" +
+		"a=rtpmap:99 h263-1998/90000\r\n"
+	if short != want {
+		t.Errorf("want %q, got %q", want, short)
+	}
+	if len(full)-len(short) != len(str)-len(want) {
+		t.Errorf("want %d bytes saved, got %d", len(str)-len(want), len(full)-len(short))
+	}
+	g, err := ParseString(short)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(g.Medias) != 2 || g.Session.Name != f.Session.Name {
+		t.Errorf("minified output not parsed back")
+	}
+	if f.Session.Info == "" || f.Medias[0].Info == "" || len(f.Email) == 0 {
+		t.Errorf("file modified by Dump")
+	}
+}
+
+func TestDumpExplicitMediaConn(t *testing.T) {
+	f := MustParse("v=0\r\n" +
+		"o=- 1 1 IN IP4 10.0.0.1\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 224.2.17.12/127\r\n" +
+		"t=0 0\r\n" +
+		"m=audio 5000 RTP/AVP 0\r\n" +
+		"m=video 5002 RTP/AVP 96\r\n" +
+		"c=IN IP4 224.2.17.13/64\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n")
+	opts := DefaultDumpOptions()
+	opts.ExplicitMediaConn = true
+	str := opts.Dump(f)
+	want := "m=audio 5000 RTP/AVP 0\r\n" +
+		"c=IN IP4 224.2.17.12/127\r\n" +
+		"m=video 5002 RTP/AVP 96\r\n" +
+		"c=IN IP4 224.2.17.13/64\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n"
+	if !strings.HasSuffix(str, want) {
+		t.Errorf("want %q at the end of %q", want, str)
+	}
+	if !strings.Contains(str, "\r\nc=IN IP4 224.2.17.12/127\r\nt=0 0\r\n") {
+		t.Errorf("session c= line not written: %q", str)
+	}
+	if n := strings.Count(f.Dump(), "c=IN"); n != 2 {
+		t.Errorf("default options: want 2 c= lines, got %d", n)
+	}
+	if !f.Medias[0].ConnInfo.IsZero() {
+		t.Errorf("file modified by Dump")
+	}
+}
+
+func TestDumpAttributeFilter(t *testing.T) {
+	f := MustParse(offerHead +
+		"a=ice-options:trickle\r\n" +
+		"a=candidate:0 1 UDP 2130706431 10.0.0.1 4000 typ host\r\n" +
+		"m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=mid:a\r\n" +
+		"a=candidate:1 1 UDP 2130706431 10.0.0.1 5000 typ host\r\n" +
+		"a=candidate:2 1 UDP 1694498815 203.0.113.1 6000 typ srflx raddr 10.0.0.1 rport 5000\r\n" +
+		"a=end-of-candidates\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n" +
+		"m=video 9 UDP/TLS/RTP/SAVPF 96\r\n" +
+		"a=mid:v\r\n" +
+		"a=candidate:3 1 UDP 2130706431 10.0.0.1 5002 typ host\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n")
+	scopes := make(map[Scope]int)
+	opts := DefaultDumpOptions()
+	opts.AttributeFilter = func(s Scope, a Attribute) bool {
+		if a.Name == "candidate" {
+			scopes[s]++
+			return false
+		}
+		return true
+	}
+	str := opts.Dump(f)
+	if strings.Contains(str, "a=candidate") {
+		t.Errorf("candidates written: %q", str)
+	}
+	want := offerHead +
+		"a=ice-options:trickle\r\n" +
+		"m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=mid:a\r\n" +
+		"a=end-of-candidates\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n" +
+		"m=video 9 UDP/TLS/RTP/SAVPF 96\r\n" +
+		"a=mid:v\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n"
+	if str != want {
+		t.Errorf("want %q, got %q", want, str)
+	}
+	if scopes[ScopeSession] != 1 || scopes[ScopeMedia] != 3 {
+		t.Errorf("unexpected scopes %v", scopes)
+	}
+	if n := strings.Count(f.Dump(), "a=candidate"); n != 4 {
+		t.Errorf("file modified by Dump: %d candidates left", n)
+	}
+}
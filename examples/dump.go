@@ -3,7 +3,6 @@ package main
 import (
 	"flag"
 	"fmt"
-	// "io"
 	"os"
 
 	"github.com/midbel/sdp"
@@ -11,14 +10,7 @@ import (
 
 func main() {
 	flag.Parse()
-	r, err := os.Open(flag.Arg(0))
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "open:", err)
-		os.Exit(1)
-	}
-	defer r.Close()
-
-	f, err := sdp.Parse(r)
+	f, err := sdp.ParseFile(flag.Arg(0))
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "parse:", err)
 		os.Exit(2)
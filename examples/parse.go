@@ -26,12 +26,9 @@ func main() {
 	// fmt.Printf("%+v\n", f)
 	fmt.Println("---")
 	fmt.Println("medias:")
-	for _, m := range f.Medias {
-		addr := m.ConnInfo.Addr
-		if addr == "" {
-			addr = f.ConnInfo.Addr
-		}
-		fmt.Printf("- %s: %s", m.Media, addr)
+	f.EachMedia(func(_ int, m sdp.MediaInfo, ctx sdp.MediaContext) error {
+		fmt.Printf("- %s: %s", m.Media, ctx.ConnInfo.Addr)
 		fmt.Println()
-	}
+		return nil
+	})
 }
@@ -0,0 +1,110 @@
+package sdp
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ExtMap is the value of an extmap attribute (RFC 8285). Direction is empty
+// when the attribute does not give one.
+type ExtMap struct {
+	ID        int
+	Direction Direction
+	URI       string
+	Params    string
+}
+
+func (e ExtMap) String() string {
+	var str strings.Builder
+	str.WriteString(strconv.Itoa(e.ID))
+	if e.Direction != "" {
+		str.WriteByte('/')
+		str.WriteString(string(e.Direction))
+	}
+	str.WriteByte(' ')
+	str.WriteString(e.URI)
+	if e.Params != "" {
+		str.WriteByte(' ')
+		str.WriteString(e.Params)
+	}
+	return str.String()
+}
+
+// ExtMaps returns the header extensions of m. An error wrapping ErrInvalid is
+// returned if an extmap has an invalid id or direction or if its URI is not an
+// absolute URI. In strict mode, Parse fails on such attributes.
+func (m MediaInfo) ExtMaps() ([]ExtMap, error) {
+	var arr []ExtMap
+	for _, a := range m.Attributes {
+		if a.Name != "extmap" {
+			continue
+		}
+		e, err := parseExtMap(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, e)
+	}
+	return arr, nil
+}
+
+// ExtMapAllowMixed reports whether one-byte and two-byte RTP header extensions
+// can be mixed in the same stream (RFC 8285).
+func (f File) ExtMapAllowMixed() bool {
+	return f.HasFlag("extmap-allow-mixed")
+}
+
+func (f *File) SetExtMapAllowMixed(on bool) {
+	f.Attributes = setFlag(f.Attributes, "extmap-allow-mixed", on)
+}
+
+// extmap:<value>["/"<direction>] <URI> <extensionattributes>
+func parseExtMap(str string) (ExtMap, error) {
+	var (
+		e     ExtMap
+		parts = strings.SplitN(str, " ", 3)
+		err   error
+	)
+	if len(parts) < 2 {
+		return e, fmt.Errorf("%w: extmap (%s)", ErrSyntax, str)
+	}
+	id := parts[0]
+	if x := strings.Index(id, "/"); x >= 0 {
+		e.Direction = Direction(id[x+1:])
+		switch e.Direction {
+		case SendRecv, SendOnly, RecvOnly, Inactive:
+		default:
+			return e, fmt.Errorf("%w: extmap direction (%s)", ErrInvalid, id[x+1:])
+		}
+		id = id[:x]
+	}
+	if e.ID, err = strconv.Atoi(id); err != nil {
+		return e, fmt.Errorf("%w - extmap id: %s", ErrSyntax, err)
+	}
+	if e.ID <= 0 || e.ID > 4351 {
+		return e, fmt.Errorf("%w: extmap id out of range (%d)", ErrInvalid, e.ID)
+	}
+	e.URI = parts[1]
+	if !validURI(e.URI) {
+		return e, fmt.Errorf("%w: extmap uri (%s)", ErrInvalid, e.URI)
+	}
+	if len(parts) == 3 {
+		e.Params = parts[2]
+	}
+	return e, nil
+}
+
+// validURI reports whether str is an absolute URI. url.Parse does not check the
+// escapes of opaque URIs (eg: urn:...) so they are checked separately.
+func validURI(str string) bool {
+	u, err := url.Parse(str)
+	if err != nil || u.Scheme == "" {
+		return false
+	}
+	if u.Opaque != "" {
+		_, err = url.PathUnescape(u.Opaque)
+	}
+	return err == nil
+}
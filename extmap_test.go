@@ -0,0 +1,86 @@
+package sdp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExtMapAllowMixed(t *testing.T) {
+	f := MustParse(offerHead + "m=audio 5000 RTP/AVP 0\r\n")
+	if f.ExtMapAllowMixed() {
+		t.Fatalf("flag set without attribute")
+	}
+	f.SetExtMapAllowMixed(true)
+	f.SetExtMapAllowMixed(true)
+	str := f.Dump()
+	if strings.Count(str, "a=extmap-allow-mixed\r\n") != 1 || strings.Contains(str, "extmap-allow-mixed:") {
+		t.Fatalf("flag not written once without colon: %q", str)
+	}
+	g, err := ParseString(str)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !g.ExtMapAllowMixed() {
+		t.Errorf("flag lost by round trip")
+	}
+	g.SetExtMapAllowMixed(false)
+	if g.ExtMapAllowMixed() || strings.Contains(g.Dump(), "extmap-allow-mixed") {
+		t.Errorf("flag not removed")
+	}
+}
+
+func TestExtMapURI(t *testing.T) {
+	data := []struct {
+		Line string
+		Want ExtMap
+		Err  error
+	}{
+		{
+			Line: "1 urn:ietf:params:rtp-hdrext:ssrc-audio-level",
+			Want: ExtMap{ID: 1, URI: "urn:ietf:params:rtp-hdrext:ssrc-audio-level"},
+		},
+		{
+			Line: "2/sendonly http://www.webrtc.org/experiments/rtp-hdrext/abs-send-time",
+			Want: ExtMap{ID: 2, Direction: SendOnly, URI: "http://www.webrtc.org/experiments/rtp-hdrext/abs-send-time"},
+		},
+		{
+			Line: "3 urn:ietf:params:rtp-hdrext:encrypt urn:ietf:params:rtp-hdrext:smpte-tc 25@600/24",
+			Want: ExtMap{ID: 3, URI: "urn:ietf:params:rtp-hdrext:encrypt", Params: "urn:ietf:params:rtp-hdrext:smpte-tc 25@600/24"},
+		},
+		{Line: "1 /rtp-hdrext/ssrc-audio-level", Err: ErrInvalid},
+		{Line: "1 urn:ietf:params:rtp-hdrext:ssrc%zzaudio-level", Err: ErrInvalid},
+		{Line: "1 http://[::1/ext", Err: ErrInvalid},
+		{Line: "1/sendonly ://abs-send-time", Err: ErrInvalid},
+	}
+	for _, d := range data {
+		str := offerHead + "m=audio 5000 RTP/AVP 0\r\na=extmap:" + d.Line + "\r\n"
+		f, err := ParseString(str)
+		if d.Err != nil {
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%s: strict: expected %s, got %v", d.Line, d.Err, err)
+			}
+			f, err := ParseString(str, WithLenient())
+			if err != nil {
+				t.Errorf("%s: lenient: unexpected error: %s", d.Line, err)
+				continue
+			}
+			if _, err := f.Medias[0].ExtMaps(); !errors.Is(err, d.Err) {
+				t.Errorf("%s: ExtMaps: expected %s, got %v", d.Line, d.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Line, err)
+			continue
+		}
+		es, err := f.Medias[0].ExtMaps()
+		if err != nil || len(es) != 1 || es[0] != d.Want {
+			t.Errorf("%s: want %+v, got %+v (%v)", d.Line, d.Want, es, err)
+			continue
+		}
+		if es[0].String() != d.Line {
+			t.Errorf("%s: round trip failed: got %s", d.Line, es[0])
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package sdp
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	GroupBundle = "BUNDLE"
+	GroupLS     = "LS"
+)
+
+// Group is the value of a group attribute (RFC 5888).
+type Group struct {
+	Semantics string
+	MIDs      []string
+}
+
+func (g Group) String() string {
+	if len(g.MIDs) == 0 {
+		return g.Semantics
+	}
+	return g.Semantics + " " + strings.Join(g.MIDs, " ")
+}
+
+func (g Group) Has(mid string) bool {
+	for _, m := range g.MIDs {
+		if m == mid {
+			return true
+		}
+	}
+	return false
+}
+
+func (f File) Groups() ([]Group, error) {
+	var arr []Group
+	for _, a := range f.Attributes {
+		if a.Name != "group" {
+			continue
+		}
+		g, err := parseGroup(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, g)
+	}
+	return arr, nil
+}
+
+// GroupsBy returns the groups using the given semantics.
+func (f File) GroupsBy(semantics string) ([]Group, error) {
+	gs, err := f.Groups()
+	if err != nil {
+		return nil, err
+	}
+	var arr []Group
+	for _, g := range gs {
+		if g.Semantics == semantics {
+			arr = append(arr, g)
+		}
+	}
+	return arr, nil
+}
+
+func (m MediaInfo) MID() (string, bool) {
+	a, ok := findAttributes("mid", m.Attributes)
+	return a.Value, ok
+}
+
+// MediaByMID returns the index of the media identified by mid or -1 if no
+// media uses it.
+func (f File) MediaByMID(mid string) int {
+	for i := range f.Medias {
+		if m, ok := f.Medias[i].MID(); ok && m == mid {
+			return i
+		}
+	}
+	return -1
+}
+
+// MIDs returns the mid of each media of f in order. An error is returned if a
+// media has no mid.
+func (f File) MIDs() ([]string, error) {
+	var arr []string
+	for i, m := range f.Medias {
+		mid, ok := m.MID()
+		if !ok {
+			return nil, fmt.Errorf("%w: media #%d (%s) without mid", ErrInvalid, i, m.Media)
+		}
+		arr = append(arr, mid)
+	}
+	return arr, nil
+}
+
+// ApplyMediaUpdate replaces the media of f having the same mid as update and
+// increments the version of the origin. An error is returned when update has
+// no mid or when no media of f uses it.
+func (f *File) ApplyMediaUpdate(update MediaInfo) error {
+	mid, ok := update.MID()
+	if !ok {
+		return fmt.Errorf("%w: media update without mid", ErrInvalid)
+	}
+	i := f.MediaByMID(mid)
+	if i < 0 {
+		return fmt.Errorf("%w: unknown mid %s", ErrInvalid, mid)
+	}
+	f.Medias[i] = update
+	f.BumpVersion()
+	return nil
+}
+
+// LipSyncGroups resolves the mids of each LS group to the medias they identify.
+// The medias are referenced and not copied. An error is returned if a mid does
+// not identify any media.
+func (f File) LipSyncGroups() ([][]*MediaInfo, error) {
+	gs, err := f.GroupsBy(GroupLS)
+	if err != nil {
+		return nil, err
+	}
+	var arr [][]*MediaInfo
+	for _, g := range gs {
+		var ms []*MediaInfo
+		for _, mid := range g.MIDs {
+			i := f.MediaByMID(mid)
+			if i < 0 {
+				return nil, fmt.Errorf("%w: LS: unknown mid %s", ErrInvalid, mid)
+			}
+			ms = append(ms, &f.Medias[i])
+		}
+		arr = append(arr, ms)
+	}
+	return arr, nil
+}
+
+// group:<semantics> *(<identification-tag>)
+func parseGroup(str string) (Group, error) {
+	var (
+		g     Group
+		parts = strings.Fields(str)
+	)
+	if len(parts) == 0 {
+		return g, fmt.Errorf("%w: group (%s)", ErrSyntax, str)
+	}
+	g.Semantics = parts[0]
+	g.MIDs = append(g.MIDs, parts[1:]...)
+	return g, nil
+}
+
+// OrderMediasByGroup reorders the medias of f to follow the order of the
+// first BUNDLE group. Medias not part of the group keep their relative order
+// and are moved after the bundled medias. f is left unchanged if a mid of the
+// group does not identify any media.
+func (f *File) OrderMediasByGroup() error {
+	gs, err := f.GroupsBy(GroupBundle)
+	if err != nil || len(gs) == 0 {
+		return err
+	}
+	xs, err := f.groupMedias(gs[0])
+	if err != nil {
+		return err
+	}
+	var (
+		arr  []MediaInfo
+		used = make(map[int]bool)
+	)
+	for _, i := range xs {
+		used[i] = true
+		arr = append(arr, f.Medias[i])
+	}
+	for i := range f.Medias {
+		if !used[i] {
+			arr = append(arr, f.Medias[i])
+		}
+	}
+	f.Medias = arr
+	return nil
+}
+
+// groupMedias returns the indexes of the medias identified by the mids of g in
+// the order of the group. A media listed more than once is only returned the
+// first time.
+func (f File) groupMedias(g Group) ([]int, error) {
+	var (
+		arr  []int
+		used = make(map[int]bool)
+	)
+	for _, mid := range g.MIDs {
+		i := f.MediaByMID(mid)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s: unknown mid %s", ErrInvalid, g.Semantics, mid)
+		}
+		if used[i] {
+			continue
+		}
+		used[i] = true
+		arr = append(arr, i)
+	}
+	return arr, nil
+}
@@ -0,0 +1,175 @@
+package sdp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBundleMIDs(t *testing.T) {
+	f := MustParse(offerHead +
+		"a=group:BUNDLE 0 audio a1 01\r\n" +
+		"m=audio 5000 RTP/AVP 0\r\na=mid:0\r\n" +
+		"m=audio 5002 RTP/AVP 8\r\na=mid:audio\r\n" +
+		"m=video 5004 RTP/AVP 96\r\na=rtpmap:96 VP8/90000\r\na=mid:a1\r\n" +
+		"m=audio 5006 RTP/AVP 0\r\na=mid:01\r\n")
+	gs, err := f.GroupsBy(GroupBundle)
+	if err != nil || len(gs) != 1 {
+		t.Fatalf("expected one BUNDLE group, got %v (%v)", gs, err)
+	}
+	for i, mid := range gs[0].MIDs {
+		if x := f.MediaByMID(mid); x != i {
+			t.Errorf("%s: want media #%d, got #%d", mid, i, x)
+		}
+		if got, _ := f.Medias[i].MID(); got != mid {
+			t.Errorf("%s: mid modified: %s", mid, got)
+		}
+	}
+	if x := f.MediaByMID("1"); x >= 0 {
+		t.Errorf("mid 01 matched by 1")
+	}
+
+	const str = "m=audio 5000 RTP/AVP 0\r\na=mid:a\"b\r\n"
+	if _, err := ParseString(offerHead + str); !errors.Is(err, ErrInvalid) {
+		t.Errorf("strict: expected %s, got %v", ErrInvalid, err)
+	}
+	g, err := ParseString(offerHead+str, WithLenient())
+	if err != nil {
+		t.Fatalf("lenient: unexpected error: %s", err)
+	}
+	if mid, _ := g.Medias[0].MID(); mid != "a\"b" {
+		t.Errorf("lenient: got mid %s", mid)
+	}
+}
+
+func TestOrderMediasByGroup(t *testing.T) {
+	const medias = "m=audio 5000 RTP/AVP 0\r\na=mid:a\r\n" +
+		"m=application 5002 UDP/DTLS/SCTP webrtc-datachannel\r\na=mid:d\r\n" +
+		"m=video 5004 RTP/AVP 96\r\na=rtpmap:96 VP8/90000\r\na=mid:v\r\n" +
+		"m=audio 5006 RTP/AVP 8\r\n" +
+		"m=video 5008 RTP/AVP 96\r\na=rtpmap:96 VP8/90000\r\na=mid:w\r\n"
+	data := []struct {
+		Group string
+		Ports []uint16
+		Err   bool
+	}{
+		{Group: "a=group:BUNDLE v a\r\n", Ports: []uint16{5004, 5000, 5002, 5006, 5008}},
+		{Group: "a=group:BUNDLE w d v a\r\n", Ports: []uint16{5008, 5002, 5004, 5000, 5006}},
+		{Group: "a=group:LS w a\r\na=group:BUNDLE d\r\na=group:BUNDLE w\r\n", Ports: []uint16{5002, 5000, 5004, 5006, 5008}},
+		{Group: "", Ports: []uint16{5000, 5002, 5004, 5006, 5008}},
+		{Group: "a=group:BUNDLE v x a\r\n", Ports: []uint16{5000, 5002, 5004, 5006, 5008}, Err: true},
+	}
+	for _, d := range data {
+		f := MustParse(offerHead + d.Group + medias)
+		err := f.OrderMediasByGroup()
+		if d.Err != errors.Is(err, ErrInvalid) {
+			t.Errorf("%q: unexpected error: %v", d.Group, err)
+		}
+		if len(f.Medias) != len(d.Ports) {
+			t.Errorf("%q: want %d medias, got %d", d.Group, len(d.Ports), len(f.Medias))
+			continue
+		}
+		for i, m := range f.Medias {
+			if m.Port != d.Ports[i] {
+				t.Errorf("%q: #%d: want port %d, got %d", d.Group, i, d.Ports[i], m.Port)
+			}
+		}
+	}
+}
+
+func TestLipSyncGroups(t *testing.T) {
+	const medias = "m=audio 5000 RTP/AVP 0\r\na=mid:1\r\n" +
+		"m=video 5002 RTP/AVP 96\r\na=rtpmap:96 VP8/90000\r\na=mid:2\r\n" +
+		"m=audio 5004 RTP/AVP 0\r\na=mid:3\r\n"
+	f := MustParse(offerHead + "a=group:LS 1 2\r\n" + medias)
+	gs, err := f.LipSyncGroups()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(gs) != 1 || len(gs[0]) != 2 {
+		t.Fatalf("want one pair, got %v", gs)
+	}
+	if gs[0][0].Media != "audio" || gs[0][1].Media != "video" {
+		t.Errorf("unexpected medias: %s, %s", gs[0][0].Media, gs[0][1].Media)
+	}
+	gs[0][1].Port = 6000
+	if f.Medias[1].Port != 6000 {
+		t.Errorf("media copied instead of referenced")
+	}
+
+	f = MustParse(offerHead + "a=group:BUNDLE 1 2 3\r\n" + medias)
+	if gs, err := f.LipSyncGroups(); err != nil || len(gs) != 0 {
+		t.Errorf("unexpected LS groups: %v (%v)", gs, err)
+	}
+
+	f = MustParse(offerHead + "a=group:LS 1 4\r\n" + medias)
+	if _, err := f.LipSyncGroups(); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected %s, got %v", ErrInvalid, err)
+	}
+}
+
+func TestApplyMediaUpdate(t *testing.T) {
+	f := MustParse(offerHead +
+		"m=audio 5000 RTP/AVP 0\r\na=mid:a\r\na=sendrecv\r\n" +
+		"m=video 5002 RTP/AVP 96\r\na=mid:v\r\na=sendrecv\r\na=rtpmap:96 VP8/90000\r\n")
+	ver := f.Session.Ver
+	update := f.Medias[1]
+	update.Attributes = append([]Attribute(nil), update.Attributes...)
+	update.SetDirection(SendOnly)
+	if err := f.ApplyMediaUpdate(update); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if f.Session.Ver != ver+1 {
+		t.Errorf("want version %d, got %d", ver+1, f.Session.Ver)
+	}
+	if d, _ := f.Medias[1].Direction(); d != SendOnly {
+		t.Errorf("video: want %s, got %s", SendOnly, d)
+	}
+	if d, _ := f.Medias[0].Direction(); d != SendRecv {
+		t.Errorf("audio: direction modified: %s", d)
+	}
+	if !strings.Contains(f.Dump(), "o=alice 1 2 IN IP4 10.0.0.1\r\n") {
+		t.Errorf("version not written: %q", f.Dump())
+	}
+
+	data := []MediaInfo{
+		{Media: "video", Port: 5002, Proto: "RTP/AVP"},
+		{Media: "video", Port: 5002, Proto: "RTP/AVP", Attributes: []Attribute{{Name: "mid", Value: "x"}}},
+	}
+	for _, m := range data {
+		if err := f.ApplyMediaUpdate(m); !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected %s, got %v", ErrInvalid, err)
+		}
+	}
+	if f.Session.Ver != ver+1 {
+		t.Errorf("version bumped by a failed update")
+	}
+}
+
+func TestMIDs(t *testing.T) {
+	f := MustParse(offerHead +
+		"m=audio 5000 RTP/AVP 0\r\na=mid:a\r\n" +
+		"m=video 5002 RTP/AVP 96\r\na=rtpmap:96 VP8/90000\r\na=mid:1\r\n" +
+		"m=application 0 UDP/DTLS/SCTP webrtc-datachannel\r\na=mid:data\r\n")
+	mids, err := f.MIDs()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Join(mids, " ") != "a 1 data" {
+		t.Errorf("unexpected mids %v", mids)
+	}
+
+	f = MustParse(offerHead +
+		"m=audio 5000 RTP/AVP 0\r\na=mid:a\r\n" +
+		"m=video 5002 RTP/AVP 96\r\na=rtpmap:96 VP8/90000\r\n")
+	mids, err = f.MIDs()
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected %s, got %v", ErrInvalid, err)
+	}
+	if len(mids) != 0 || !strings.Contains(err.Error(), "media #1 (video)") {
+		t.Errorf("unexpected result: %v (%s)", mids, err)
+	}
+	if mids, err := MustParse(offerHead).MIDs(); err != nil || len(mids) != 0 {
+		t.Errorf("session without media: got %v (%v)", mids, err)
+	}
+}
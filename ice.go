@@ -0,0 +1,345 @@
+package sdp
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	CandidateHost  = "host"
+	CandidateSrflx = "srflx"
+	CandidatePrflx = "prflx"
+	CandidateRelay = "relay"
+)
+
+type Candidate struct {
+	Foundation string
+	Component  int
+	Transport  string
+	Priority   uint32
+	Addr       string
+	Port       uint16
+	Type       string
+	RelAddr    string
+	RelPort    uint16
+	Extensions []Attribute
+}
+
+func (c Candidate) String() string {
+	var str strings.Builder
+	str.WriteString(c.Foundation)
+	str.WriteByte(' ')
+	str.WriteString(strconv.Itoa(c.Component))
+	str.WriteByte(' ')
+	str.WriteString(c.Transport)
+	str.WriteByte(' ')
+	str.WriteString(strconv.FormatUint(uint64(c.Priority), 10))
+	str.WriteByte(' ')
+	str.WriteString(c.Addr)
+	str.WriteByte(' ')
+	str.WriteString(strconv.FormatUint(uint64(c.Port), 10))
+	str.WriteString(" typ ")
+	str.WriteString(c.Type)
+	if c.RelAddr != "" {
+		str.WriteString(" raddr ")
+		str.WriteString(c.RelAddr)
+		str.WriteString(" rport ")
+		str.WriteString(strconv.FormatUint(uint64(c.RelPort), 10))
+	}
+	for _, e := range c.Extensions {
+		str.WriteByte(' ')
+		str.WriteString(e.Name)
+		str.WriteByte(' ')
+		str.WriteString(e.Value)
+	}
+	return str.String()
+}
+
+// AddCandidate appends c to the candidates of the media identified by mid, as
+// done when candidates are trickled (RFC 8840). The candidate is inserted
+// before the end-of-candidates attribute if the media has one.
+func (f *File) AddCandidate(mid string, c Candidate) error {
+	i := f.MediaByMID(mid)
+	if i < 0 {
+		return fmt.Errorf("%w: unknown mid %s", ErrInvalid, mid)
+	}
+	var (
+		m = &f.Medias[i]
+		a = Attribute{Name: "candidate", Value: c.String()}
+	)
+	if x := indexAttribute("end-of-candidates", m.Attributes); x >= 0 {
+		attrs := make([]Attribute, 0, len(m.Attributes)+1)
+		attrs = append(attrs, m.Attributes[:x]...)
+		attrs = append(attrs, a)
+		m.Attributes = append(attrs, m.Attributes[x:]...)
+		return nil
+	}
+	m.Attributes = append(m.Attributes, a)
+	return nil
+}
+
+func (m MediaInfo) Candidates() ([]Candidate, error) {
+	var arr []Candidate
+	for _, a := range m.Attributes {
+		if a.Name != "candidate" {
+			continue
+		}
+		c, err := parseCandidate(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, c)
+	}
+	return arr, nil
+}
+
+// CandidatesByPriority returns the candidates of m from the highest priority to
+// the lowest one. Candidates with the same priority are ordered by component
+// and then by foundation.
+func (m MediaInfo) CandidatesByPriority() ([]Candidate, error) {
+	arr, err := m.Candidates()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(arr, func(i, j int) bool {
+		a, b := arr[i], arr[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		return a.Foundation < b.Foundation
+	})
+	return arr, nil
+}
+
+// candidate:<foundation> <component-id> <transport> <priority> <connection-address> <port> typ <cand-type> [raddr <addr>] [rport <port>] *(<name> <value>)
+//
+// fields are separated by spaces only: the colons found in IPv6 addresses are
+// part of the connection-address field.
+func parseCandidate(str string) (Candidate, error) {
+	var (
+		c     Candidate
+		parts = strings.Fields(str)
+		err   error
+	)
+	if len(parts) < 8 || parts[6] != "typ" {
+		return c, fmt.Errorf("%w: candidate (%s)", ErrSyntax, str)
+	}
+	c.Foundation = parts[0]
+	if c.Component, err = strconv.Atoi(parts[1]); err != nil {
+		return c, fmt.Errorf("%w - candidate component: %s", ErrSyntax, err)
+	}
+	c.Transport = parts[2]
+	n, err := strconv.ParseUint(parts[3], 10, 32)
+	if err != nil {
+		return c, fmt.Errorf("%w - candidate priority: %s", ErrSyntax, err)
+	}
+	c.Priority = uint32(n)
+	c.Addr = parts[4]
+	if c.Port, err = parsePort(parts[5]); err != nil {
+		return c, err
+	}
+	c.Type = parts[7]
+
+	parts = parts[8:]
+	if len(parts)%2 != 0 {
+		return c, fmt.Errorf("%w: candidate extension without value (%s)", ErrSyntax, str)
+	}
+	for i := 0; i < len(parts); i += 2 {
+		switch name, value := parts[i], parts[i+1]; name {
+		case "raddr":
+			c.RelAddr = value
+		case "rport":
+			if c.RelPort, err = parsePort(value); err != nil {
+				return c, err
+			}
+		default:
+			c.Extensions = append(c.Extensions, Attribute{Name: name, Value: value})
+		}
+	}
+	return c, nil
+}
+
+func parsePort(str string) (uint16, error) {
+	n, err := strconv.ParseUint(str, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid port %q", ErrSyntax, str)
+	}
+	return uint16(n), nil
+}
+
+const (
+	ICEControlling = "controlling"
+	ICEControlled  = "controlled"
+)
+
+func (f File) ICELite() bool {
+	return f.HasFlag("ice-lite")
+}
+
+// EndOfCandidates reports whether all the candidates of m have been gathered
+// (RFC 8840).
+func (m MediaInfo) EndOfCandidates() bool {
+	return m.HasFlag("end-of-candidates")
+}
+
+// ICERole guesses the ICE role of the agent that generated f. A lite agent is
+// always controlled. Otherwise, the role is derived from the setup attribute:
+// actpass is only used in an offer and the offerer takes the controlling role
+// while active and passive are answers. An empty string is returned when no
+// decision can be made.
+func (f File) ICERole() string {
+	if f.ICELite() {
+		return ICEControlled
+	}
+	a, ok := findAttributes("setup", f.Attributes)
+	for i := 0; !ok && i < len(f.Medias); i++ {
+		a, ok = findAttributes("setup", f.Medias[i].Attributes)
+	}
+	if !ok {
+		return ""
+	}
+	switch a.Value {
+	case "actpass":
+		return ICEControlling
+	case "active", "passive":
+		return ICEControlled
+	default:
+		return ""
+	}
+}
+
+var webrtcAttributes = map[string]struct{}{
+	"candidate":         {},
+	"remote-candidates": {},
+	"end-of-candidates": {},
+	"fingerprint":       {},
+	"setup":             {},
+	"tls-id":            {},
+	"bundle-only":       {},
+}
+
+// StripWebRTC removes the ICE (ice-*, candidates) and DTLS attributes of f and
+// replaces the DTLS protocols of its medias by RTP/AVP, making it usable by
+// plain RTP endpoints. Plain RTP endpoints do not bundle medias: the BUNDLE
+// groups and the bundle-only attributes are removed too.
+//
+// Ports and connection addresses are left untouched. With ICE, they are often
+// placeholders (eg: port 9, 0.0.0.0) and a bundle-only media has a zero port
+// that a plain RTP endpoint understands as rejected: setting them to the
+// addresses of the RTP endpoint is up to the caller.
+func (f *File) StripWebRTC() {
+	f.WalkAttributes(func(_ Scope, a Attribute) (Attribute, bool) {
+		if a.Name == "group" {
+			g, err := parseGroup(a.Value)
+			return a, err != nil || g.Semantics != GroupBundle
+		}
+		_, ok := webrtcAttributes[a.Name]
+		return a, !ok && !hasNamePrefix(a, "ice-")
+	})
+	for i := range f.Medias {
+		switch f.Medias[i].Proto {
+		case "UDP/TLS/RTP/SAVPF", "UDP/TLS/RTP/SAVP":
+			f.Medias[i].Proto = "RTP/AVP"
+		}
+	}
+}
+
+var transportAttributes = []string{
+	"ice-ufrag",
+	"ice-pwd",
+	"ice-options",
+	"fingerprint",
+	"setup",
+	"candidate",
+	"end-of-candidates",
+	"rtcp-mux",
+}
+
+// InheritTransport copies the transport attributes (ICE credentials and
+// candidates, DTLS fingerprint and setup, rtcp-mux) of src to m, as done for
+// the medias sharing the transport of the first media of a BUNDLE group. An
+// attribute is only copied when m does not have it yet.
+func (m *MediaInfo) InheritTransport(src MediaInfo) {
+	for _, name := range transportAttributes {
+		if _, ok := findAttributes(name, m.Attributes); ok {
+			continue
+		}
+		for _, a := range src.Attributes {
+			if a.Name == name {
+				m.Attributes = append(m.Attributes, a)
+			}
+		}
+	}
+}
+
+// ValidateICE checks that each media using ICE has the ICE credentials
+// (ice-ufrag and ice-pwd, at the media or at the session level) and at least
+// one candidate. A media uses ICE when it is transported over DTLS or when an
+// ICE attribute is found at the session level or in the media. Rejected
+// medias are not checked and a bundle-only media does not need candidates to
+// be valid. A media of a BUNDLE group uses the transport of the first media of
+// the group.
+func (f File) ValidateICE() error {
+	groups, err := f.GroupsBy(GroupBundle)
+	if err != nil {
+		return err
+	}
+	tagged := make(map[string]int)
+	for _, g := range groups {
+		if len(g.MIDs) == 0 {
+			continue
+		}
+		x := f.MediaByMID(g.MIDs[0])
+		if x < 0 {
+			continue
+		}
+		for _, mid := range g.MIDs {
+			tagged[mid] = x
+		}
+	}
+	var (
+		vs      []Violation
+		session = hasICE(f.Attributes)
+	)
+	for i, m := range f.Medias {
+		if m.Port == 0 {
+			continue
+		}
+		src := m
+		if mid, ok := m.MID(); ok {
+			if x, ok := tagged[mid]; ok {
+				src = f.Medias[x]
+			}
+		}
+		if !session && !m.UsesDTLS() && !hasICE(m.Attributes) && !hasICE(src.Attributes) {
+			continue
+		}
+		missing := func(what string) {
+			vs = append(vs, Violation{
+				Rule:    RuleICE,
+				Scope:   ScopeMedia,
+				Message: fmt.Sprintf("media #%d (%s): missing %s", i, m.Media, what),
+			})
+		}
+		for _, name := range []string{"ice-ufrag", "ice-pwd"} {
+			if _, ok := findAttributes(name, src.Attributes); ok {
+				continue
+			}
+			if _, ok := findAttributes(name, f.Attributes); !ok {
+				missing(name)
+			}
+		}
+		if m.BundleOnly() {
+			continue
+		}
+		if _, ok := findAttributes("candidate", src.Attributes); !ok {
+			missing("candidate")
+		}
+	}
+	return violationsError(vs)
+}
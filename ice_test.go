@@ -0,0 +1,317 @@
+package sdp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateICE(t *testing.T) {
+	const (
+		creds = "a=ice-ufrag:F7gI\r\na=ice-pwd:x9cml/YzichV2+XlhiMu8g\r\n"
+		host  = "a=candidate:1 1 UDP 2130706431 10.0.0.1 5000 typ host\r\n"
+	)
+	data := []struct {
+		Name    string
+		Input   string
+		Missing []string
+	}{
+		{
+			Name:  "plain rtp",
+			Input: "m=audio 5000 RTP/AVP 0\r\n",
+		},
+		{
+			Name:  "complete",
+			Input: "m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" + creds + host,
+		},
+		{
+			Name:  "session credentials",
+			Input: creds + "m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" + host,
+		},
+		{
+			Name:    "missing pwd",
+			Input:   "m=audio 9 UDP/TLS/RTP/SAVPF 111\r\na=ice-ufrag:F7gI\r\n" + host,
+			Missing: []string{"ice-pwd"},
+		},
+		{
+			Name:    "missing candidates",
+			Input:   "m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" + creds,
+			Missing: []string{"candidate"},
+		},
+		{
+			Name:    "ice over rtp/avp",
+			Input:   "m=audio 5000 RTP/AVP 0\r\n" + host,
+			Missing: []string{"ice-ufrag", "ice-pwd"},
+		},
+		{
+			Name:  "rejected",
+			Input: "m=audio 0 UDP/TLS/RTP/SAVPF 111\r\n",
+		},
+		{
+			Name: "bundled",
+			Input: "a=group:BUNDLE a v\r\n" +
+				"m=audio 9 UDP/TLS/RTP/SAVPF 111\r\na=mid:a\r\n" + creds + host +
+				"m=video 9 UDP/TLS/RTP/SAVPF 96\r\na=mid:v\r\n" +
+				"m=video 0 UDP/TLS/RTP/SAVPF 96\r\na=mid:w\r\na=bundle-only\r\n",
+		},
+		{
+			Name: "bundle-only",
+			Input: "m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" + creds + host +
+				"m=video 9 UDP/TLS/RTP/SAVPF 96\r\n" + creds + "a=bundle-only\r\n",
+		},
+	}
+	for _, d := range data {
+		err := MustParse(offerHead + d.Input).ValidateICE()
+		if len(d.Missing) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", d.Name, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected %s, got %v", d.Name, ErrInvalid, err)
+			continue
+		}
+		if got := strings.Count(err.Error(), "missing"); got != len(d.Missing) {
+			t.Errorf("%s: want %d fields missing, got %s", d.Name, len(d.Missing), err)
+		}
+		for _, what := range d.Missing {
+			if !strings.Contains(err.Error(), "media #0 (audio): missing "+what) {
+				t.Errorf("%s: %s not reported: %s", d.Name, what, err)
+			}
+		}
+	}
+}
+
+func TestStripWebRTC(t *testing.T) {
+	f := MustParse(offerHead +
+		"a=group:BUNDLE a v\r\n" +
+		"a=group:LS a v\r\n" +
+		"a=ice-options:trickle\r\n" +
+		"a=fingerprint:sha-256 AB:CD:EF\r\n" +
+		"m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=mid:a\r\n" +
+		"a=ice-ufrag:F7gI\r\n" +
+		"a=ice-pwd:x9cml/YzichV2+XlhiMu8g\r\n" +
+		"a=setup:actpass\r\n" +
+		"a=candidate:1 1 UDP 2130706431 10.0.0.1 5000 typ host\r\n" +
+		"a=end-of-candidates\r\n" +
+		"a=rtcp-mux\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n" +
+		"m=video 0 UDP/TLS/RTP/SAVPF 96\r\n" +
+		"a=mid:v\r\n" +
+		"a=bundle-only\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n")
+	f.StripWebRTC()
+	if err := f.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	f.WalkAttributes(func(s Scope, a Attribute) (Attribute, bool) {
+		switch a.Name {
+		case "candidate", "end-of-candidates", "fingerprint", "setup", "bundle-only":
+			t.Errorf("%s: %s not removed", s, a.Name)
+		}
+		if strings.HasPrefix(a.Name, "ice-") {
+			t.Errorf("%s: %s not removed", s, a.Name)
+		}
+		if a.Name == "group" && strings.HasPrefix(a.Value, GroupBundle) {
+			t.Errorf("%s: BUNDLE group not removed", s)
+		}
+		return a, true
+	})
+	if gs, _ := f.GroupsBy(GroupLS); len(gs) != 1 {
+		t.Errorf("LS group removed")
+	}
+	for i, m := range f.Medias {
+		if m.Proto != "RTP/AVP" {
+			t.Errorf("media #%d: proto %s", i, m.Proto)
+		}
+	}
+	if _, ok := findAttributes("rtcp-mux", f.Medias[0].Attributes); !ok {
+		t.Errorf("rtcp-mux removed")
+	}
+}
+
+func TestICERole(t *testing.T) {
+	data := []struct {
+		Lite  bool
+		Setup string
+		Role  string
+	}{
+		{Setup: "actpass", Role: ICEControlling},
+		{Setup: "active", Role: ICEControlled},
+		{Setup: "passive", Role: ICEControlled},
+		{},
+		{Lite: true, Setup: "actpass", Role: ICEControlled},
+		{Lite: true, Setup: "active", Role: ICEControlled},
+		{Lite: true, Setup: "passive", Role: ICEControlled},
+		{Lite: true, Role: ICEControlled},
+	}
+	for _, d := range data {
+		var str string
+		if d.Lite {
+			str += "a=ice-lite\r\n"
+		}
+		str += "m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n"
+		if d.Setup != "" {
+			str += "a=setup:" + d.Setup + "\r\n"
+		}
+		f := MustParse(offerHead + str)
+		if f.ICELite() != d.Lite {
+			t.Errorf("lite=%t setup=%s: ICELite: got %t", d.Lite, d.Setup, f.ICELite())
+		}
+		if role := f.ICERole(); role != d.Role {
+			t.Errorf("lite=%t setup=%s: want role %q, got %q", d.Lite, d.Setup, d.Role, role)
+		}
+	}
+}
+
+func TestICELiteRoundTrip(t *testing.T) {
+	f := MustParse(offerHead + "a=ice-lite\r\nm=audio 9 UDP/TLS/RTP/SAVPF 111\r\n")
+	str := f.Dump()
+	if !strings.Contains(str, "\r\na=ice-lite\r\n") {
+		t.Fatalf("ice-lite not written as a flag: %q", str)
+	}
+	g, err := ParseString(str)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !g.ICELite() || g.Medias[0].HasFlag("ice-lite") {
+		t.Errorf("ice-lite not kept at session level")
+	}
+}
+
+func TestAddCandidate(t *testing.T) {
+	f := MustParse(offerHead +
+		"m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=mid:audio\r\n" +
+		"a=candidate:1 1 UDP 2130706431 10.0.0.1 5000 typ host\r\n" +
+		"a=end-of-candidates\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n" +
+		"m=video 9 UDP/TLS/RTP/SAVPF 96\r\n" +
+		"a=mid:video\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n")
+	c := Candidate{
+		Foundation: "2",
+		Component:  1,
+		Transport:  "UDP",
+		Priority:   1694498815,
+		Addr:       "203.0.113.1",
+		Port:       6000,
+		Type:       CandidateSrflx,
+		RelAddr:    "10.0.0.1",
+		RelPort:    5000,
+	}
+	if err := f.AddCandidate("audio", c); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := f.AddCandidate("video", c); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	const line = "a=candidate:2 1 UDP 1694498815 203.0.113.1 6000 typ srflx raddr 10.0.0.1 rport 5000\r\n"
+	want := "m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=mid:audio\r\n" +
+		"a=candidate:1 1 UDP 2130706431 10.0.0.1 5000 typ host\r\n" +
+		line +
+		"a=end-of-candidates\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n"
+	if got := mediaText(f.Medias[0]); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if as := f.Medias[1].Attributes; as[len(as)-1].Value != c.String() {
+		t.Errorf("candidate not appended: %v", as)
+	}
+	if cs, err := f.Medias[0].Candidates(); err != nil || len(cs) != 2 || cs[1].String() != c.String() {
+		t.Errorf("candidate not parsed back: %v (%v)", cs, err)
+	}
+	if err := f.AddCandidate("data", c); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected %s, got %v", ErrInvalid, err)
+	}
+}
+
+func TestInheritTransport(t *testing.T) {
+	f := MustParse(offerHead +
+		"m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=mid:a\r\n" +
+		"a=ice-ufrag:F7gI\r\n" +
+		"a=ice-pwd:x9cml/YzichV2+XlhiMu8g\r\n" +
+		"a=fingerprint:sha-256 AB:CD:EF\r\n" +
+		"a=setup:actpass\r\n" +
+		"a=candidate:1 1 UDP 2130706431 10.0.0.1 5000 typ host\r\n" +
+		"a=candidate:2 1 UDP 1694498815 203.0.113.1 6000 typ srflx raddr 10.0.0.1 rport 5000\r\n" +
+		"a=rtcp-mux\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n" +
+		"m=video 9 UDP/TLS/RTP/SAVPF 96\r\n" +
+		"a=mid:v\r\n" +
+		"a=ice-ufrag:Kx9q\r\n" +
+		"a=setup:passive\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n")
+	m := f.Medias[1]
+	m.InheritTransport(f.Medias[0])
+
+	data := []struct {
+		Name   string
+		Values []string
+	}{
+		{Name: "ice-ufrag", Values: []string{"Kx9q"}},
+		{Name: "setup", Values: []string{"passive"}},
+		{Name: "ice-pwd", Values: []string{"x9cml/YzichV2+XlhiMu8g"}},
+		{Name: "fingerprint", Values: []string{"sha-256 AB:CD:EF"}},
+		{Name: "candidate", Values: []string{
+			"1 1 UDP 2130706431 10.0.0.1 5000 typ host",
+			"2 1 UDP 1694498815 203.0.113.1 6000 typ srflx raddr 10.0.0.1 rport 5000",
+		}},
+		{Name: "rtcp-mux", Values: []string{""}},
+	}
+	for _, d := range data {
+		var got []string
+		for _, a := range m.Attributes {
+			if a.Name == d.Name {
+				got = append(got, a.Value)
+			}
+		}
+		if strings.Join(got, "|") != strings.Join(d.Values, "|") {
+			t.Errorf("%s: want %q, got %q", d.Name, d.Values, got)
+		}
+	}
+	if mid, _ := m.MID(); mid != "v" {
+		t.Errorf("mid overwritten: %s", mid)
+	}
+	if _, ok := findAttributes("rtpmap", m.Attributes); !ok {
+		t.Errorf("rtpmap removed")
+	}
+	if len(f.Medias[1].Attributes) != 4 {
+		t.Errorf("media of the file modified: %v", f.Medias[1].Attributes)
+	}
+}
+
+func TestCandidatesByPriority(t *testing.T) {
+	f := MustParse(offerHead +
+		"m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=candidate:3 1 UDP 41885439 198.51.100.1 7000 typ relay raddr 203.0.113.1 rport 6000\r\n" +
+		"a=candidate:1 2 UDP 2130706430 10.0.0.1 5001 typ host\r\n" +
+		"a=candidate:2 1 UDP 1694498815 203.0.113.1 6000 typ srflx raddr 10.0.0.1 rport 5000\r\n" +
+		"a=candidate:1 1 UDP 2130706431 10.0.0.1 5000 typ host\r\n" +
+		"a=candidate:5 1 TCP 1694498815 203.0.113.1 9 typ srflx raddr 10.0.0.1 rport 9 tcptype active\r\n" +
+		"a=candidate:4 1 UDP 1694498815 203.0.113.2 6002 typ srflx raddr 10.0.0.1 rport 5000\r\n" +
+		"a=candidate:2 2 UDP 1694498814 203.0.113.1 6001 typ srflx raddr 10.0.0.1 rport 5001\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n")
+	cs, err := f.Medias[0].CandidatesByPriority()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []string{"1/1", "1/2", "2/1", "4/1", "5/1", "2/2", "3/1"}
+	if len(cs) != len(want) {
+		t.Fatalf("want %d candidates, got %d", len(want), len(cs))
+	}
+	for i, c := range cs {
+		got := fmt.Sprintf("%s/%d", c.Foundation, c.Component)
+		if got != want[i] {
+			t.Errorf("candidate #%d: want %s, got %s (priority %d)", i, want[i], got, c.Priority)
+		}
+	}
+	if as := f.Medias[0].Attributes; !strings.HasPrefix(as[0].Value, "3 ") {
+		t.Errorf("attributes of the media reordered")
+	}
+}
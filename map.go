@@ -0,0 +1,368 @@
+package sdp
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ToMap returns f as a tree of maps, slices, strings and integers that can be
+// given to any encoder working on generic values. Times are formatted with
+// RFC 3339 and empty values are omitted. FromMap gives f back.
+func (f File) ToMap() map[string]any {
+	m := map[string]any{
+		"version": f.Version,
+		"origin": map[string]any{
+			"user":    f.Session.User,
+			"id":      f.Session.ID,
+			"version": f.Session.Ver,
+			"conn":    connToMap(f.Session.ConnInfo),
+		},
+		"name": f.Session.Name,
+	}
+	putString(m, "info", f.Session.Info)
+	putString(m, "uri", f.Session.URI)
+	putStrings(m, "email", f.Email)
+	putStrings(m, "phone", f.Phone)
+	if !f.ConnInfo.IsZero() {
+		m["conn"] = connToMap(f.ConnInfo)
+	}
+	putBandwidths(m, f.Bandwidth)
+	putAttributes(m, f.Attributes)
+	if len(f.Intervals) > 0 {
+		var arr []any
+		for _, i := range f.Intervals {
+			im := make(map[string]any)
+			if !i.Starts.IsZero() {
+				im["start"] = i.Starts.Format(time.RFC3339)
+			}
+			if !i.Ends.IsZero() {
+				im["end"] = i.Ends.Format(time.RFC3339)
+			}
+			arr = append(arr, im)
+		}
+		m["intervals"] = arr
+	}
+	if len(f.Medias) > 0 {
+		var arr []any
+		for _, md := range f.Medias {
+			mm := map[string]any{
+				"media": md.Media,
+				"port":  int(md.Port),
+				"proto": md.Proto,
+			}
+			if md.Count > 0 {
+				mm["count"] = int(md.Count)
+			}
+			putStrings(mm, "formats", md.Attrs)
+			putString(mm, "info", md.Info)
+			if !md.ConnInfo.IsZero() {
+				mm["conn"] = connToMap(md.ConnInfo)
+			}
+			putBandwidths(mm, md.Bandwidth)
+			putAttributes(mm, md.Attributes)
+			arr = append(arr, mm)
+		}
+		m["medias"] = arr
+	}
+	return m
+}
+
+func connToMap(c ConnInfo) map[string]any {
+	m := map[string]any{
+		"net":  c.NetType,
+		"type": c.AddrType,
+		"addr": c.Addr,
+	}
+	if c.TTL > 0 {
+		m["ttl"] = c.TTL
+	}
+	if c.Count > 0 {
+		m["count"] = c.Count
+	}
+	return m
+}
+
+func putString(m map[string]any, key, value string) {
+	if value != "" {
+		m[key] = value
+	}
+}
+
+func putStrings(m map[string]any, key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	arr := make([]any, len(values))
+	for i := range values {
+		arr[i] = values[i]
+	}
+	m[key] = arr
+}
+
+func putBandwidths(m map[string]any, bws []Bandwidth) {
+	if len(bws) == 0 {
+		return
+	}
+	var arr []any
+	for _, b := range bws {
+		arr = append(arr, map[string]any{"type": b.Type, "value": b.Value})
+	}
+	m["bandwidth"] = arr
+}
+
+func putAttributes(m map[string]any, attrs []Attribute) {
+	if len(attrs) == 0 {
+		return
+	}
+	var arr []any
+	for _, a := range attrs {
+		am := map[string]any{"name": a.Name}
+		putString(am, "value", a.Value)
+		arr = append(arr, am)
+	}
+	m["attributes"] = arr
+}
+
+// FromMap builds a File from a tree of values as returned by ToMap. Numbers
+// can be of any integer or floating point type so that the result of generic
+// decoders (eg: JSON) is accepted. Floating point numbers above 2^53 are
+// refused since they may have lost precision (eg: the 63 bit session ids of
+// WebRTC): decode them as json.Number instead (see json.Decoder.UseNumber).
+func FromMap(m map[string]any) (File, error) {
+	var (
+		f File
+		d = mapDecoder{}
+	)
+	f.Version = int(d.int(m, "version"))
+	if o := d.object(m, "origin"); o != nil {
+		f.Session.User = d.string(o, "user")
+		f.Session.ID = d.int(o, "id")
+		f.Session.Ver = d.int(o, "version")
+		f.Session.ConnInfo = d.conn(o, "conn")
+	}
+	f.Session.Name = d.string(m, "name")
+	f.Session.Info = d.string(m, "info")
+	f.Session.URI = d.string(m, "uri")
+	f.Email = d.strings(m, "email")
+	f.Phone = d.strings(m, "phone")
+	f.ConnInfo = d.conn(m, "conn")
+	f.Bandwidth = d.bandwidths(m)
+	f.Attributes = d.attributes(m)
+	for _, im := range d.objects(m, "intervals") {
+		var i Interval
+		i.Starts = d.time(im, "start")
+		i.Ends = d.time(im, "end")
+		f.Intervals = append(f.Intervals, i)
+	}
+	for _, mm := range d.objects(m, "medias") {
+		var md MediaInfo
+		md.Media = d.string(mm, "media")
+		md.Port = uint16(d.uint16(mm, "port"))
+		md.Count = uint16(d.uint16(mm, "count"))
+		md.Proto = d.string(mm, "proto")
+		md.Attrs = d.strings(mm, "formats")
+		md.Info = d.string(mm, "info")
+		md.ConnInfo = d.conn(mm, "conn")
+		md.Bandwidth = d.bandwidths(mm)
+		md.Attributes = d.attributes(mm)
+		f.Medias = append(f.Medias, md)
+	}
+	return f, d.err
+}
+
+// mapDecoder reads the values of a map created by ToMap. The first error is
+// kept and the following calls do nothing.
+type mapDecoder struct {
+	err error
+}
+
+func (d *mapDecoder) fail(key string, v any) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: map: unexpected value for %s (%v)", ErrInvalid, key, v)
+	}
+}
+
+func (d *mapDecoder) string(m map[string]any, key string) string {
+	v, ok := m[key]
+	if !ok || d.err != nil {
+		return ""
+	}
+	str, ok := v.(string)
+	if !ok {
+		d.fail(key, v)
+	}
+	return str
+}
+
+func (d *mapDecoder) int(m map[string]any, key string) int64 {
+	v, ok := m[key]
+	if !ok || d.err != nil {
+		return 0
+	}
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int8:
+		return int64(n)
+	case int16:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case uint:
+		return int64(n)
+	case uint8:
+		return int64(n)
+	case uint16:
+		return int64(n)
+	case uint32:
+		return int64(n)
+	case uint64:
+		return int64(n)
+	case float32:
+		return d.float(key, float64(n))
+	case float64:
+		return d.float(key, n)
+	case interface{ Int64() (int64, error) }:
+		if x, err := n.Int64(); err == nil {
+			return x
+		}
+	}
+	d.fail(key, v)
+	return 0
+}
+
+// maxExact is the largest integer such that all the integers below it can be
+// represented exactly by a float64.
+const maxExact = 1 << 53
+
+func (d *mapDecoder) float(key string, n float64) int64 {
+	if n != math.Trunc(n) || n > maxExact || n < -maxExact {
+		d.fail(key, n)
+		return 0
+	}
+	return int64(n)
+}
+
+func (d *mapDecoder) uint16(m map[string]any, key string) int64 {
+	n := d.int(m, key)
+	if n < 0 || n > 65535 {
+		d.fail(key, n)
+	}
+	return n
+}
+
+func (d *mapDecoder) time(m map[string]any, key string) time.Time {
+	str := d.string(m, key)
+	if str == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		d.fail(key, str)
+	}
+	return t
+}
+
+func (d *mapDecoder) list(m map[string]any, key string) []any {
+	v, ok := m[key]
+	if !ok || d.err != nil {
+		return nil
+	}
+	switch vs := v.(type) {
+	case []any:
+		return vs
+	case []string:
+		arr := make([]any, len(vs))
+		for i := range vs {
+			arr[i] = vs[i]
+		}
+		return arr
+	case []map[string]any:
+		arr := make([]any, len(vs))
+		for i := range vs {
+			arr[i] = vs[i]
+		}
+		return arr
+	}
+	d.fail(key, v)
+	return nil
+}
+
+func (d *mapDecoder) strings(m map[string]any, key string) []string {
+	var arr []string
+	for _, v := range d.list(m, key) {
+		str, ok := v.(string)
+		if !ok {
+			d.fail(key, v)
+			return nil
+		}
+		arr = append(arr, str)
+	}
+	return arr
+}
+
+func (d *mapDecoder) object(m map[string]any, key string) map[string]any {
+	v, ok := m[key]
+	if !ok || d.err != nil {
+		return nil
+	}
+	o, ok := v.(map[string]any)
+	if !ok {
+		d.fail(key, v)
+	}
+	return o
+}
+
+func (d *mapDecoder) objects(m map[string]any, key string) []map[string]any {
+	var arr []map[string]any
+	for _, v := range d.list(m, key) {
+		o, ok := v.(map[string]any)
+		if !ok {
+			d.fail(key, v)
+			return nil
+		}
+		arr = append(arr, o)
+	}
+	return arr
+}
+
+func (d *mapDecoder) conn(m map[string]any, key string) ConnInfo {
+	var (
+		c ConnInfo
+		o = d.object(m, key)
+	)
+	if o == nil {
+		return c
+	}
+	c.NetType = d.string(o, "net")
+	c.AddrType = d.string(o, "type")
+	c.Addr = d.string(o, "addr")
+	c.TTL = d.int(o, "ttl")
+	c.Count = d.int(o, "count")
+	return c
+}
+
+func (d *mapDecoder) bandwidths(m map[string]any) []Bandwidth {
+	var arr []Bandwidth
+	for _, o := range d.objects(m, "bandwidth") {
+		arr = append(arr, Bandwidth{
+			Type:  d.string(o, "type"),
+			Value: d.int(o, "value"),
+		})
+	}
+	return arr
+}
+
+func (d *mapDecoder) attributes(m map[string]any) []Attribute {
+	var arr []Attribute
+	for _, o := range d.objects(m, "attributes") {
+		arr = append(arr, Attribute{
+			Name:  d.string(o, "name"),
+			Value: d.string(o, "value"),
+		})
+	}
+	return arr
+}
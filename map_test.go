@@ -0,0 +1,123 @@
+package sdp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const mapSample = "v=0\r\n" +
+	"o=jdoe 2890844526 2890842807 IN IP4 10.47.16.5\r\n" +
+	"s=SDP Seminar\r\n" +
+	"i=A Seminar on the session description protocol\r\n" +
+	"u=http://www.example.com/seminars/sdp.pdf\r\n" +
+	"e=j.doe@example.com (Jane Doe)\r\n" +
+	"p=+1 617 555-6011\r\n" +
+	"c=IN IP4 224.2.17.12/127/2\r\n" +
+	"b=AS:256\r\n" +
+	"t=2873397496 2873404696\r\n" +
+	"t=0 0\r\n" +
+	"a=recvonly\r\n" +
+	"a=tool:sdp\r\n" +
+	"m=audio 49170/2 RTP/AVP 0 101\r\n" +
+	"i=audio\r\n" +
+	"c=IN IP6 ff15::101/3\r\n" +
+	"b=TIAS:64000\r\n" +
+	"a=rtpmap:101 telephone-event/8000\r\n" +
+	"a=fmtp:101 0-15\r\n" +
+	"m=video 0 RTP/AVP 99\r\n" +
+	"a=rtpmap:99 h263-1998/90000\r\n"
+
+func TestMapRoundTrip(t *testing.T) {
+	f := MustParse(mapSample)
+	got, err := FromMap(f.ToMap())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := f.Dump(); got.Dump() != want {
+		t.Errorf("want %q, got %q", want, got.Dump())
+	}
+}
+
+func TestMapRoundTripJSON(t *testing.T) {
+	f := MustParse(mapSample)
+	buf, err := json.Marshal(f.ToMap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(buf, &m); err != nil {
+		t.Fatal(err)
+	}
+	got, err := FromMap(m)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := f.Dump(); got.Dump() != want {
+		t.Errorf("want %q, got %q", want, got.Dump())
+	}
+
+	// session ids of WebRTC use 63 bits and can not be decoded as float64
+	// without losing precision
+	f.Session.ID = 8497074555582468711
+	buf, _ = json.Marshal(f.ToMap())
+	m = nil
+	json.Unmarshal(buf, &m)
+	if _, err := FromMap(m); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected %s for inexact float, got %v", ErrInvalid, err)
+	}
+	m = nil
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	got, err = FromMap(m)
+	if err != nil {
+		t.Fatalf("json.Number: unexpected error: %s", err)
+	}
+	if got.Session.ID != f.Session.ID || got.Dump() != f.Dump() {
+		t.Errorf("json.Number: want id %d, got %d", f.Session.ID, got.Session.ID)
+	}
+}
+
+func TestFromMapNumbers(t *testing.T) {
+	data := []struct {
+		Port any
+		Err  bool
+	}{
+		{Port: 9},
+		{Port: int64(9)},
+		{Port: uint16(9)},
+		{Port: float64(9)},
+		{Port: float32(9)},
+		{Port: json.Number("9")},
+		{Port: 9.5, Err: true},
+		{Port: float64(70000), Err: true},
+		{Port: -1, Err: true},
+		{Port: "9", Err: true},
+		{Port: json.Number("9.5"), Err: true},
+	}
+	for _, d := range data {
+		m := map[string]any{
+			"medias": []any{
+				map[string]any{"media": "audio", "port": d.Port, "proto": "RTP/AVP"},
+			},
+		}
+		f, err := FromMap(m)
+		if d.Err {
+			if !errors.Is(err, ErrInvalid) {
+				t.Errorf("%#v: expected %s, got %v", d.Port, ErrInvalid, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%#v: unexpected error: %s", d.Port, err)
+			continue
+		}
+		if f.Medias[0].Port != 9 {
+			t.Errorf("%#v: want port 9, got %d", d.Port, f.Medias[0].Port)
+		}
+	}
+}
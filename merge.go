@@ -0,0 +1,130 @@
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Merge combines the capabilities of several sessions (eg: the answers of the
+// branches of a forked SIP request) in a single session. The session level
+// fields are the ones of the first file and the session attributes of the
+// other files are added when not already present.
+//
+// Medias are matched by mid, or by position for the medias without mid. A
+// media found in a single file is kept as is. Otherwise:
+//
+//   - the formats of the medias are combined. For RTP medias, a codec already
+//     present under another payload number is not added twice,
+//   - the attributes of the medias are combined, duplicates are dropped.
+//
+// An error wrapping ErrInvalid is returned when the medias matched do not
+// have the same type and protocol or when a payload number is used for two
+// different codecs.
+func Merge(files ...File) (File, error) {
+	if len(files) == 0 {
+		return File{}, fmt.Errorf("%w: no session to merge", ErrInvalid)
+	}
+	merged := files[0]
+	merged.Attributes = append([]Attribute(nil), files[0].Attributes...)
+	merged.Medias = nil
+
+	index := make(map[string]int)
+	for j, f := range files {
+		if j > 0 {
+			merged.Attributes = mergeAttributes(merged.Attributes, f.Attributes)
+		}
+		for i, m := range f.Medias {
+			key, ok := m.MID()
+			if !ok {
+				key = "#" + strconv.Itoa(i)
+			}
+			x, ok := index[key]
+			if !ok {
+				index[key] = len(merged.Medias)
+				m.Attrs = append([]string(nil), m.Attrs...)
+				m.Attributes = append([]Attribute(nil), m.Attributes...)
+				merged.Medias = append(merged.Medias, m)
+				continue
+			}
+			if err := mergeMedia(&merged.Medias[x], m); err != nil {
+				return merged, fmt.Errorf("media %s: %w", key, err)
+			}
+		}
+	}
+	return merged, nil
+}
+
+func mergeMedia(dst *MediaInfo, src MediaInfo) error {
+	if dst.Media != src.Media || dst.Proto != src.Proto {
+		return fmt.Errorf("%w: can not merge %s %s with %s %s", ErrInvalid, dst.Media, dst.Proto, src.Media, src.Proto)
+	}
+	if !dst.UsesRTP() {
+		for _, f := range src.Attrs {
+			if !hasFormat(dst.Attrs, f) {
+				dst.Attrs = append(dst.Attrs, f)
+			}
+		}
+		dst.Attributes = mergeAttributes(dst.Attributes, src.Attributes)
+		return nil
+	}
+	var (
+		have, _ = dst.Formats()
+		from, _ = src.Formats()
+		skip    = make(map[string]bool)
+	)
+	for _, r := range from {
+		var (
+			payload = strconv.Itoa(int(r.Payload))
+			present bool
+		)
+		for _, c := range have {
+			if c.Same(r) {
+				present, skip[payload] = true, c.Payload != r.Payload
+				break
+			}
+		}
+		if !present {
+			if c, ok := findRTPMap(r.Payload, have); ok {
+				return fmt.Errorf("%w: payload %d used for %s and %s", ErrInvalid, r.Payload, c, r)
+			}
+		}
+		if !skip[payload] && !hasFormat(dst.Attrs, payload) {
+			dst.Attrs = append(dst.Attrs, payload)
+			have = append(have, r)
+		}
+	}
+	var attrs []Attribute
+	for _, a := range src.Attributes {
+		if isPayloadAttribute(a.Name) && skip[payloadOf(a.Value)] {
+			continue
+		}
+		attrs = append(attrs, a)
+	}
+	dst.Attributes = mergeAttributes(dst.Attributes, attrs)
+	return nil
+}
+
+func mergeAttributes(dst, src []Attribute) []Attribute {
+	for _, a := range src {
+		var found bool
+		for _, d := range dst {
+			if d == a {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, a)
+		}
+	}
+	return dst
+}
+
+func hasFormat(formats []string, format string) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
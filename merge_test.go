@@ -0,0 +1,71 @@
+package sdp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	a := MustParse(offerHead +
+		"m=audio 5000 RTP/AVP 96 97 0\r\n" +
+		"a=mid:a\r\n" +
+		"a=rtpmap:96 opus/48000/2\r\n" +
+		"a=fmtp:96 minptime=10\r\n" +
+		"a=rtpmap:97 telephone-event/8000\r\n")
+	b := MustParse(localHead +
+		"m=audio 6000 RTP/AVP 96 97 98 0\r\n" +
+		"a=mid:a\r\n" +
+		"a=rtpmap:96 telephone-event/8000\r\n" +
+		"a=rtpmap:97 opus/48000/2\r\n" +
+		"a=fmtp:97 useinbandfec=1\r\n" +
+		"a=rtpmap:98 G722/8000\r\n" +
+		"m=video 6002 RTP/AVP 100\r\n" +
+		"a=mid:v\r\n" +
+		"a=rtpmap:100 VP8/90000\r\n")
+
+	f, err := Merge(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(f.Medias) != 2 {
+		t.Fatalf("want 2 medias, got %d", len(f.Medias))
+	}
+	want := "m=audio 5000 RTP/AVP 96 97 0 98\r\n" +
+		"a=mid:a\r\n" +
+		"a=rtpmap:96 opus/48000/2\r\n" +
+		"a=fmtp:96 minptime=10\r\n" +
+		"a=rtpmap:97 telephone-event/8000\r\n" +
+		"a=rtpmap:98 G722/8000\r\n"
+	if got := mediaText(f.Medias[0]); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if mid, _ := f.Medias[1].MID(); mid != "v" {
+		t.Errorf("distinct media not added: %s", mid)
+	}
+	if f.Session.User != "alice" {
+		t.Errorf("session not taken from the first file: %s", f.Session.User)
+	}
+
+	data := []struct {
+		Name  string
+		Media string
+	}{
+		{
+			Name:  "payload collision",
+			Media: "m=audio 6000 RTP/AVP 96\r\na=mid:a\r\na=rtpmap:96 G722/8000\r\n",
+		},
+		{
+			Name:  "proto",
+			Media: "m=audio 6000 RTP/SAVP 96\r\na=mid:a\r\na=rtpmap:96 opus/48000/2\r\n",
+		},
+	}
+	for _, d := range data {
+		_, err := Merge(a, MustParse(localHead+d.Media))
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected %s, got %v", d.Name, ErrInvalid, err)
+		} else if !strings.Contains(err.Error(), "media a") {
+			t.Errorf("%s: media not reported: %s", d.Name, err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package sdp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+const MimeType = "application/sdp"
+
+// ParseMIME parses every application/sdp part found in a MIME body described
+// by contentType. Parts of any other type are skipped. Nested multipart bodies
+// are traversed in order.
+func ParseMIME(r io.Reader, contentType string) ([]File, error) {
+	mediatype, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case mediatype == MimeType:
+		f, err := Parse(r)
+		if err != nil {
+			return nil, err
+		}
+		return []File{f}, nil
+	case strings.HasPrefix(mediatype, "multipart/"):
+		return parseMultipart(r, params["boundary"])
+	default:
+		return nil, fmt.Errorf("%w: unsupported content type %s", ErrInvalid, mediatype)
+	}
+}
+
+func parseMultipart(r io.Reader, boundary string) ([]File, error) {
+	if boundary == "" {
+		return nil, fmt.Errorf("%w: missing multipart boundary", ErrInvalid)
+	}
+	var (
+		mr  = multipart.NewReader(r, boundary)
+		arr []File
+	)
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return arr, err
+		}
+		mediatype, params, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if err != nil {
+			continue
+		}
+		switch {
+		case mediatype == MimeType:
+			f, err := Parse(part)
+			if err != nil {
+				return arr, err
+			}
+			arr = append(arr, f)
+		case strings.HasPrefix(mediatype, "multipart/"):
+			fs, err := parseMultipart(part, params["boundary"])
+			arr = append(arr, fs...)
+			if err != nil {
+				return arr, err
+			}
+		}
+	}
+	return arr, nil
+}
+
+// ParseRTSP parses the session carried by the response of a RTSP DESCRIBE
+// request. The status line and the headers are skipped and the value of the
+// Content-Base header, if any, is stored in the ContentBase field of the
+// returned File.
+func ParseRTSP(r io.Reader, opts ...Option) (File, error) {
+	tp := textproto.NewReader(bufio.NewReader(r))
+	line, err := tp.ReadLine()
+	if err != nil {
+		return File{}, fmt.Errorf("%w: rtsp: missing status line", ErrSyntax)
+	}
+	if !strings.HasPrefix(line, "RTSP/") {
+		return File{}, fmt.Errorf("%w: rtsp: invalid status line (%s)", ErrSyntax, line)
+	}
+	hdr, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return File{}, fmt.Errorf("%w: rtsp: missing body", ErrSyntax)
+	}
+	var body io.Reader = tp.R
+	if str := hdr.Get("Content-Length"); str != "" {
+		n, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return File{}, fmt.Errorf("%w: rtsp: invalid content length (%s)", ErrSyntax, str)
+		}
+		body = io.LimitReader(body, n)
+	}
+	f, err := Parse(body, opts...)
+	f.ContentBase = hdr.Get("Content-Base")
+	return f, err
+}
@@ -0,0 +1,60 @@
+package sdp
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseRTSP(t *testing.T) {
+	const body = "v=0\r\n" +
+		"o=- 1234 1 IN IP4 192.168.1.10\r\n" +
+		"s=Camera\r\n" +
+		"c=IN IP4 0.0.0.0\r\n" +
+		"t=0 0\r\n" +
+		"a=control:*\r\n" +
+		"m=video 0 RTP/AVP 96\r\n" +
+		"a=rtpmap:96 H264/90000\r\n" +
+		"a=fmtp:96 packetization-mode=1\r\n" +
+		"a=control:trackID=1\r\n"
+	resp := "RTSP/1.0 200 OK\r\n" +
+		"CSeq: 2\r\n" +
+		"Content-Base: rtsp://192.168.1.10:554/stream/\r\n" +
+		"Content-Type: application/sdp\r\n" +
+		"Content-Length: " + strconv.Itoa(len(body)) + "\r\n" +
+		"\r\n" +
+		body
+
+	f, err := ParseRTSP(strings.NewReader(resp))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if f.ContentBase != "rtsp://192.168.1.10:554/stream/" {
+		t.Errorf("content base: got %q", f.ContentBase)
+	}
+	if f.Session.Name != "Camera" || len(f.Medias) != 1 {
+		t.Errorf("session not parsed: %s", f.Dump())
+	}
+	if f.Dump() != MustParse(body).Dump() {
+		t.Errorf("body not parsed as is: %q", f.Dump())
+	}
+	if _, err := ParseRTSP(strings.NewReader(resp + "RTSP/1.0 200 OK\r\n")); err != nil {
+		t.Errorf("data after Content-Length not ignored: %s", err)
+	}
+
+	data := []struct {
+		Name  string
+		Input string
+	}{
+		{Name: "empty", Input: ""},
+		{Name: "status line", Input: "HTTP/1.1 200 OK\r\n\r\n" + body},
+		{Name: "no blank line", Input: "RTSP/1.0 200 OK\r\nCSeq: 2\r\n"},
+		{Name: "content length", Input: "RTSP/1.0 200 OK\r\nContent-Length: x\r\n\r\n" + body},
+	}
+	for _, d := range data {
+		if _, err := ParseRTSP(strings.NewReader(d.Input)); !errors.Is(err, ErrSyntax) {
+			t.Errorf("%s: expected %s, got %v", d.Name, ErrSyntax, err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package sdp
+
+import (
+	"strings"
+)
+
+// AcceptTypes returns the media types accepted by a MSRP media (RFC 4975). The
+// list can contain wildcards like text/* or *.
+func (m MediaInfo) AcceptTypes() ([]string, bool) {
+	return typesOf(m.Attributes, "accept-types")
+}
+
+// AcceptWrappedTypes returns the media types only accepted inside a wrapper
+// type like message/cpim.
+func (m MediaInfo) AcceptWrappedTypes() ([]string, bool) {
+	return typesOf(m.Attributes, "accept-wrapped-types")
+}
+
+// MSRPPath returns the path attribute of m: the MSRP URIs to reach the
+// endpoint, separated by spaces.
+func (m MediaInfo) MSRPPath() (string, bool) {
+	a, ok := findAttributes("path", m.Attributes)
+	return a.Value, ok
+}
+
+func typesOf(attrs []Attribute, name string) ([]string, bool) {
+	a, ok := findAttributes(name, attrs)
+	if !ok {
+		return nil, false
+	}
+	return strings.Fields(a.Value), true
+}
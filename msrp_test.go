@@ -0,0 +1,43 @@
+package sdp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMSRP(t *testing.T) {
+	const media = "m=message 7654 TCP/MSRP *\r\n" +
+		"a=accept-types:message/cpim text/plain text/*\r\n" +
+		"a=accept-wrapped-types:text/plain image/*\r\n" +
+		"a=path:msrp://atlanta.example.com:7654/jshA7weztas;tcp msrp://biloxi.example.com:12763/kjhd37s2s20w2a;tcp\r\n" +
+		"m=message 7656 TCP/TLS/MSRP *\r\n" +
+		"a=accept-types:*\r\n"
+	f := MustParse(offerHead + media)
+	m := f.Medias[0]
+	types, ok := m.AcceptTypes()
+	if !ok || strings.Join(types, " ") != "message/cpim text/plain text/*" {
+		t.Errorf("accept-types: got %v (%t)", types, ok)
+	}
+	types, ok = m.AcceptWrappedTypes()
+	if !ok || strings.Join(types, " ") != "text/plain image/*" {
+		t.Errorf("accept-wrapped-types: got %v (%t)", types, ok)
+	}
+	path, ok := m.MSRPPath()
+	if !ok || len(strings.Fields(path)) != 2 || !strings.HasPrefix(path, "msrp://atlanta.example.com:7654/") {
+		t.Errorf("path: got %s (%t)", path, ok)
+	}
+
+	m = f.Medias[1]
+	if types, ok := m.AcceptTypes(); !ok || len(types) != 1 || types[0] != "*" {
+		t.Errorf("wildcard: got %v (%t)", types, ok)
+	}
+	if _, ok := m.AcceptWrappedTypes(); ok {
+		t.Errorf("accept-wrapped-types set without attribute")
+	}
+	if _, ok := m.MSRPPath(); ok {
+		t.Errorf("path set without attribute")
+	}
+	if str := f.Dump(); !strings.HasSuffix(str, media) {
+		t.Errorf("MSRP medias not written back: %q", str)
+	}
+}
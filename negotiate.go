@@ -0,0 +1,322 @@
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Answer builds the answer to offer (RFC 3264) from the capabilities of the
+// local endpoint described by local.
+//
+// The answer takes its origin, name and connection information from local and
+// its timing from offer. Each media of the offer is matched against the first
+// unused media of local with the same type and protocol. A media is rejected
+// (port set to zero) when no local media matches or when both have no format
+// in common. For accepted media:
+//
+//   - the codecs are the ones in common, with the payload numbers of the offer,
+//   - the direction is derived from the directions of both sides,
+//   - rtcp-mux and bundle-only are only set if both sides set them,
+//   - only the header extensions also supported locally are kept, with the
+//     identifiers of the offer,
+//   - the other attributes of the local media are copied.
+//
+// Finally, the answer has a BUNDLE group for each BUNDLE group of the offer
+// with the mids of the medias accepted.
+func Answer(offer, local File) (File, error) {
+	answer := File{
+		Session:   local.Session,
+		ConnInfo:  local.ConnInfo,
+		Bandwidth: local.Bandwidth,
+	}
+	answer.Intervals = append(answer.Intervals, offer.Intervals...)
+	if len(answer.Intervals) == 0 {
+		answer.Intervals = append(answer.Intervals, Interval{})
+	}
+	for _, a := range local.Attributes {
+		if a.Name == "group" || isDirection(a.Name) {
+			continue
+		}
+		answer.Attributes = append(answer.Attributes, a)
+	}
+	used := make(map[int]bool)
+	for _, om := range offer.Medias {
+		var (
+			am MediaInfo
+			ok bool
+		)
+		for i, lm := range local.Medias {
+			if used[i] || lm.Media != om.Media || lm.Proto != om.Proto {
+				continue
+			}
+			if am, ok = answerMedia(offer, om, local, lm); ok {
+				used[i] = true
+				break
+			}
+		}
+		if !ok {
+			am = rejectMedia(om)
+		}
+		if am.Port != 0 && am.ConnInfo.IsZero() && answer.ConnInfo.IsZero() {
+			return answer, fmt.Errorf("%w: media %s without connection information", ErrInvalid, am.Media)
+		}
+		answer.Medias = append(answer.Medias, am)
+	}
+	groups, err := offer.GroupsBy(GroupBundle)
+	if err != nil {
+		return answer, err
+	}
+	for _, g := range groups {
+		bundle := Group{Semantics: GroupBundle}
+		for _, mid := range g.MIDs {
+			if i := answer.MediaByMID(mid); i >= 0 && answer.Medias[i].Port != 0 {
+				bundle.MIDs = append(bundle.MIDs, mid)
+			}
+		}
+		if len(bundle.MIDs) > 0 {
+			answer.Attributes = append(answer.Attributes, Attribute{Name: "group", Value: bundle.String()})
+		}
+	}
+	return answer, nil
+}
+
+// BundleAnswer is like Answer but the medias of each BUNDLE group share the
+// transport of the first media of the group (RFC 8843): the other medias of
+// the group get the port and the address of the first one and their transport
+// attributes (see InheritTransport) are moved to the first media, if it does
+// not have them yet. The first media is the first one of the group, which is
+// not necessarily the first m= line: the medias keep the order of the offer as
+// an answer must (RFC 3264).
+func BundleAnswer(offer, local File) (File, error) {
+	answer, err := Answer(offer, local)
+	if err != nil {
+		return answer, err
+	}
+	groups, err := answer.GroupsBy(GroupBundle)
+	if err != nil {
+		return answer, err
+	}
+	for _, g := range groups {
+		xs, err := answer.groupMedias(g)
+		if err != nil {
+			return answer, err
+		}
+		if len(xs) == 0 {
+			continue
+		}
+		tag := xs[0]
+		for _, i := range xs[1:] {
+			answer.Medias[tag].InheritTransport(answer.Medias[i])
+			for _, name := range transportAttributes {
+				answer.Medias[i].RemoveAttribute(name)
+			}
+			answer.Medias[i].Port = answer.Medias[tag].Port
+			answer.Medias[i].ConnInfo = answer.Medias[tag].ConnInfo
+		}
+	}
+	return answer, nil
+}
+
+// RejectAll builds an answer to offer rejecting all of its medias. The origin
+// and the name of the session are the ones of offer, with the version of the
+// origin incremented.
+func RejectAll(offer File) File {
+	answer := File{
+		Session:   offer.Session,
+		ConnInfo:  offer.Session.ConnInfo,
+		Intervals: []Interval{{}},
+	}
+	if answer.ConnInfo.IsZero() {
+		answer.ConnInfo = offer.ConnInfo
+	}
+	answer.Session.Info = ""
+	answer.Session.URI = ""
+	answer.BumpVersion()
+	for _, m := range offer.Medias {
+		answer.Medias = append(answer.Medias, rejectMedia(m))
+	}
+	return answer
+}
+
+func rejectMedia(m MediaInfo) MediaInfo {
+	r := MediaInfo{
+		Media: m.Media,
+		Proto: m.Proto,
+	}
+	r.Attrs = append(r.Attrs, m.Attrs...)
+	if mid, ok := m.MID(); ok {
+		r.Attributes = append(r.Attributes, Attribute{Name: "mid", Value: mid})
+	}
+	return r
+}
+
+var negotiated = map[string]struct{}{
+	"mid":         {},
+	"rtpmap":      {},
+	"fmtp":        {},
+	"rtcp-fb":     {},
+	"rtcp-mux":    {},
+	"bundle-only": {},
+	"extmap":      {},
+	"setup":       {},
+}
+
+func answerMedia(offer File, om MediaInfo, local File, lm MediaInfo) (MediaInfo, bool) {
+	am := MediaInfo{
+		Media:    om.Media,
+		Port:     lm.Port,
+		Proto:    om.Proto,
+		ConnInfo: lm.ConnInfo,
+	}
+	am.Bandwidth = append(am.Bandwidth, lm.Bandwidth...)
+	if lm.Port == 0 {
+		return am, false
+	}
+	if mid, ok := om.MID(); ok {
+		am.Attributes = append(am.Attributes, Attribute{Name: "mid", Value: mid})
+	}
+	dir := offer.EffectiveDirection(om).Negotiate(local.EffectiveDirection(lm))
+	am.Attributes = append(am.Attributes, Attribute{Name: string(dir)})
+	if om.RTCPMux() && lm.RTCPMux() {
+		am.Attributes = append(am.Attributes, Attribute{Name: "rtcp-mux"})
+	}
+	if om.BundleOnly() && lm.BundleOnly() {
+		am.Attributes = append(am.Attributes, Attribute{Name: "bundle-only"})
+	}
+	am.Attributes = append(am.Attributes, answerExtMaps(om, lm)...)
+	if setup, ok := answerSetup(offer, om, local, lm); ok {
+		am.Attributes = append(am.Attributes, Attribute{Name: "setup", Value: setup})
+	}
+
+	if om.UsesRTP() {
+		codecs := CodecsInCommon(om, lm)
+		if len(codecs) == 0 {
+			return am, false
+		}
+		locals, _ := lm.Formats()
+		for _, c := range codecs {
+			var (
+				payload = strconv.Itoa(int(c.Payload))
+				from    string
+			)
+			for _, l := range locals {
+				if c.Same(l) {
+					from = strconv.Itoa(int(l.Payload))
+					break
+				}
+			}
+			am.Attrs = append(am.Attrs, payload)
+			am.Attributes = append(am.Attributes, Attribute{Name: "rtpmap", Value: c.String()})
+			for _, a := range lm.Attributes {
+				if (a.Name != "fmtp" && a.Name != "rtcp-fb") || payloadOf(a.Value) != from {
+					continue
+				}
+				a.Value = payload + a.Value[len(from):]
+				am.Attributes = append(am.Attributes, a)
+			}
+		}
+		for _, a := range lm.Attributes {
+			if a.Name == "rtcp-fb" && payloadOf(a.Value) == "*" {
+				am.Attributes = append(am.Attributes, a)
+			}
+		}
+	} else {
+		for _, f := range om.Attrs {
+			for _, l := range lm.Attrs {
+				if f == l {
+					am.Attrs = append(am.Attrs, f)
+					break
+				}
+			}
+		}
+		if len(am.Attrs) == 0 {
+			return am, false
+		}
+	}
+	for _, a := range lm.Attributes {
+		if _, ok := negotiated[a.Name]; ok || isDirection(a.Name) {
+			continue
+		}
+		am.Attributes = append(am.Attributes, a)
+	}
+	return am, true
+}
+
+func answerExtMaps(om, lm MediaInfo) []Attribute {
+	offered, err := om.ExtMaps()
+	if err != nil {
+		return nil
+	}
+	supported, err := lm.ExtMaps()
+	if err != nil {
+		return nil
+	}
+	var arr []Attribute
+	for _, o := range offered {
+		for _, s := range supported {
+			if o.URI != s.URI {
+				continue
+			}
+			e := o
+			if o.Direction != "" || s.Direction != "" {
+				od, sd := o.Direction, s.Direction
+				if od == "" {
+					od = SendRecv
+				}
+				if sd == "" {
+					sd = SendRecv
+				}
+				if e.Direction = od.Negotiate(sd); e.Direction == SendRecv {
+					e.Direction = ""
+				}
+			}
+			arr = append(arr, Attribute{Name: "extmap", Value: e.String()})
+			break
+		}
+	}
+	return arr
+}
+
+// answerSetup chooses the DTLS role of the answerer (RFC 5763): when the
+// offerer can take both roles, the answerer keeps its role if it has one and
+// becomes active otherwise.
+func answerSetup(offer File, om MediaInfo, local File, lm MediaInfo) (string, bool) {
+	lookup := func(f File, m MediaInfo) string {
+		if a, ok := findAttributes("setup", m.Attributes); ok {
+			return a.Value
+		}
+		a, _ := findAttributes("setup", f.Attributes)
+		return a.Value
+	}
+	var (
+		offerSetup = lookup(offer, om)
+		localSetup = lookup(local, lm)
+	)
+	if localSetup == "" {
+		return "", false
+	}
+	switch offerSetup {
+	case "active":
+		return "passive", true
+	case "passive":
+		return "active", true
+	default:
+		if localSetup == "active" || localSetup == "passive" {
+			return localSetup, true
+		}
+		return "active", true
+	}
+}
+
+func (m MediaInfo) BundleOnly() bool {
+	return m.HasFlag("bundle-only")
+}
+
+func isDirection(name string) bool {
+	switch Direction(trimValue(name)) {
+	case SendRecv, SendOnly, RecvOnly, Inactive:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,327 @@
+package sdp
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	offerHead = "v=0\r\n" +
+		"o=alice 1 1 IN IP4 10.0.0.1\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 10.0.0.1\r\n" +
+		"t=0 0\r\n"
+	localHead = "v=0\r\n" +
+		"o=bob 2 1 IN IP4 10.0.0.2\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 10.0.0.2\r\n" +
+		"t=0 0\r\n"
+)
+
+func TestAnswer(t *testing.T) {
+	data := []struct {
+		Name  string
+		Offer string
+		Local string
+		// Want lists the lines expected in the first media of the answer and
+		// Not the ones that should not be there.
+		Port uint16
+		Want []string
+		Not  []string
+	}{
+		{
+			Name:  "rtcp-mux on both sides",
+			Offer: "m=audio 5000 RTP/AVP 0\r\na=rtcp-mux\r\n",
+			Local: "m=audio 6000 RTP/AVP 0\r\na=rtcp-mux\r\n",
+			Port:  6000,
+			Want:  []string{"a=rtcp-mux", "a=sendrecv"},
+		},
+		{
+			Name:  "rtcp-mux only offered",
+			Offer: "m=audio 5000 RTP/AVP 0\r\na=rtcp-mux\r\n",
+			Local: "m=audio 6000 RTP/AVP 0\r\n",
+			Port:  6000,
+			Not:   []string{"a=rtcp-mux"},
+		},
+		{
+			Name:  "rtcp-mux only local",
+			Offer: "m=audio 5000 RTP/AVP 0\r\n",
+			Local: "m=audio 6000 RTP/AVP 0\r\na=rtcp-mux\r\n",
+			Port:  6000,
+			Not:   []string{"a=rtcp-mux"},
+		},
+		{
+			Name:  "bundle-only on both sides",
+			Offer: "m=audio 5000 RTP/AVP 0\r\na=bundle-only\r\n",
+			Local: "m=audio 6000 RTP/AVP 0\r\na=bundle-only\r\n",
+			Port:  6000,
+			Want:  []string{"a=bundle-only"},
+		},
+		{
+			Name:  "bundle-only only offered",
+			Offer: "m=audio 5000 RTP/AVP 0\r\na=bundle-only\r\n",
+			Local: "m=audio 6000 RTP/AVP 0\r\n",
+			Port:  6000,
+			Not:   []string{"a=bundle-only"},
+		},
+		{
+			Name:  "bundle-only only local",
+			Offer: "m=audio 5000 RTP/AVP 0\r\n",
+			Local: "m=audio 6000 RTP/AVP 0\r\na=bundle-only\r\n",
+			Port:  6000,
+			Not:   []string{"a=bundle-only"},
+		},
+		{
+			Name: "extmap missing locally",
+			Offer: "m=audio 5000 RTP/AVP 0\r\n" +
+				"a=extmap:1 urn:ietf:params:rtp-hdrext:ssrc-audio-level\r\n" +
+				"a=extmap:3 urn:ietf:params:rtp-hdrext:sdes:mid\r\n",
+			Local: "m=audio 6000 RTP/AVP 0\r\n" +
+				"a=extmap:7/recvonly urn:ietf:params:rtp-hdrext:sdes:mid\r\n",
+			Port: 6000,
+			Want: []string{"a=extmap:3/recvonly urn:ietf:params:rtp-hdrext:sdes:mid"},
+			Not:  []string{"ssrc-audio-level", "a=extmap:7"},
+		},
+		{
+			Name: "direction",
+			Offer: "m=audio 5000 RTP/AVP 0\r\n" +
+				"a=sendonly\r\n",
+			Local: "m=audio 6000 RTP/AVP 0\r\n",
+			Port:  6000,
+			Want:  []string{"a=recvonly"},
+			Not:   []string{"a=sendrecv", "a=sendonly"},
+		},
+		{
+			Name: "codec renumbered",
+			Offer: "m=video 5000 RTP/AVP 100 101\r\n" +
+				"a=rtpmap:100 VP8/90000\r\n" +
+				"a=rtpmap:101 H264/90000\r\n",
+			Local: "m=video 6000 RTP/AVP 96\r\n" +
+				"a=rtpmap:96 h264/90000\r\n" +
+				"a=fmtp:96 packetization-mode=1\r\n" +
+				"a=rtcp-fb:96 nack pli\r\n" +
+				"a=rtcp-fb:* ccm fir\r\n",
+			Port: 6000,
+			Want: []string{
+				"m=video 6000 RTP/AVP 101",
+				"a=rtpmap:101 H264/90000",
+				"a=fmtp:101 packetization-mode=1",
+				"a=rtcp-fb:101 nack pli",
+				"a=rtcp-fb:* ccm fir",
+			},
+			Not: []string{"VP8", ":96 "},
+		},
+		{
+			Name: "no codec in common",
+			Offer: "m=audio 5000 RTP/AVP 0\r\n" +
+				"a=mid:a0\r\n",
+			Local: "m=audio 6000 RTP/AVP 8\r\n",
+			Want:  []string{"m=audio 0 RTP/AVP 0", "a=mid:a0"},
+			Not:   []string{"a=sendrecv"},
+		},
+		{
+			Name: "no local media",
+			Offer: "m=video 5000 RTP/AVP 96\r\n" +
+				"a=rtpmap:96 VP8/90000\r\n" +
+				"a=mid:v0\r\n",
+			Local: "m=audio 6000 RTP/AVP 0\r\n",
+			Want:  []string{"m=video 0 RTP/AVP 96", "a=mid:v0"},
+			Not:   []string{"a=rtpmap"},
+		},
+	}
+	for _, d := range data {
+		offer := MustParse(offerHead + d.Offer)
+		local := MustParse(localHead + d.Local)
+		answer, err := Answer(offer, local)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Name, err)
+			continue
+		}
+		if len(answer.Medias) != len(offer.Medias) {
+			t.Errorf("%s: want %d medias, got %d", d.Name, len(offer.Medias), len(answer.Medias))
+			continue
+		}
+		m := answer.Medias[0]
+		if m.Port != d.Port {
+			t.Errorf("%s: want port %d, got %d", d.Name, d.Port, m.Port)
+		}
+		lines := strings.Split(strings.TrimSpace(mediaText(m)), "\r\n")
+		for _, w := range d.Want {
+			if !hasLine(lines, w) {
+				t.Errorf("%s: %q not found in %q", d.Name, w, lines)
+			}
+		}
+		for _, n := range d.Not {
+			for _, line := range lines {
+				if strings.Contains(line, n) {
+					t.Errorf("%s: unexpected %q in %q", d.Name, n, line)
+				}
+			}
+		}
+	}
+}
+
+func TestAnswerBundle(t *testing.T) {
+	offer := MustParse(offerHead +
+		"a=group:BUNDLE a v d\r\n" +
+		"m=audio 5000 RTP/AVP 0\r\na=mid:a\r\n" +
+		"m=video 5000 RTP/AVP 96\r\na=rtpmap:96 VP8/90000\r\na=mid:v\r\n" +
+		"m=audio 5000 RTP/AVP 8\r\na=mid:d\r\n")
+	local := MustParse(localHead +
+		"m=audio 6000 RTP/AVP 0\r\n" +
+		"m=video 6000 RTP/AVP 100\r\na=rtpmap:100 VP8/90000\r\n")
+	answer, err := Answer(offer, local)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	gs, err := answer.GroupsBy(GroupBundle)
+	if err != nil || len(gs) != 1 {
+		t.Fatalf("expected one BUNDLE group, got %v (%v)", gs, err)
+	}
+	if got := strings.Join(gs[0].MIDs, " "); got != "a v" {
+		t.Errorf("rejected media kept in group: %s", got)
+	}
+	if m := answer.Medias[2]; m.Port != 0 {
+		t.Errorf("third media not rejected")
+	} else if mid, _ := m.MID(); mid != "d" {
+		t.Errorf("rejected media lost its mid")
+	}
+}
+
+func mediaText(m MediaInfo) string {
+	var str strings.Builder
+	f := File{Medias: []MediaInfo{m}}
+	f.WriteTo(&str)
+	return str.String()[strings.Index(str.String(), "m="):]
+}
+
+func hasLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBundleAnswer(t *testing.T) {
+	offer := MustParse(offerHead +
+		"a=group:BUNDLE a v\r\n" +
+		"m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=mid:a\r\na=rtcp-mux\r\na=setup:actpass\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n" +
+		"m=video 9 UDP/TLS/RTP/SAVPF 96\r\n" +
+		"a=mid:v\r\na=rtcp-mux\r\na=setup:actpass\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n")
+	local := MustParse(localHead +
+		"m=audio 6000 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=rtcp-mux\r\na=setup:passive\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n" +
+		"m=video 6002 UDP/TLS/RTP/SAVPF 96\r\n" +
+		"a=rtcp-mux\r\na=setup:passive\r\n" +
+		"a=ice-ufrag:video\r\na=ice-pwd:videovideovideovideovi\r\n" +
+		"a=fingerprint:sha-256 AB:CD\r\n" +
+		"a=candidate:1 1 udp 1 10.0.0.2 6002 typ host\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n")
+	answer, err := BundleAnswer(offer, local)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tag, other := answer.Medias[0], answer.Medias[1]
+	if cs, _ := tag.Candidates(); len(cs) != 1 {
+		t.Errorf("first media: want 1 candidate, got %d", len(cs))
+	}
+	for _, name := range []string{"ice-ufrag", "ice-pwd", "fingerprint", "setup", "rtcp-mux"} {
+		if _, ok := findAttributes(name, tag.Attributes); !ok {
+			t.Errorf("first media: %s not set", name)
+		}
+		if _, ok := findAttributes(name, other.Attributes); ok {
+			t.Errorf("second media: %s still set", name)
+		}
+	}
+	if cs, _ := other.Candidates(); len(cs) != 0 {
+		t.Errorf("second media: unexpected candidates: %v", cs)
+	}
+	if other.Port != tag.Port || !other.ConnInfo.Equal(tag.ConnInfo) {
+		t.Errorf("second media does not share the transport of the first")
+	}
+	gs, err := answer.GroupsBy(GroupBundle)
+	if err != nil || len(gs) != 1 || len(gs[0].MIDs) != 2 {
+		t.Errorf("expected one BUNDLE group with 2 mids, got %v (%v)", gs, err)
+	}
+}
+
+func TestBundleAnswerGroupOrder(t *testing.T) {
+	offer := MustParse(offerHead +
+		"a=group:BUNDLE v a v\r\n" +
+		"m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=mid:a\r\na=rtpmap:111 opus/48000/2\r\n" +
+		"m=video 9 UDP/TLS/RTP/SAVPF 96\r\n" +
+		"a=mid:v\r\na=rtpmap:96 VP8/90000\r\n")
+	local := MustParse(localHead +
+		"m=audio 6000 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=candidate:1 1 udp 1 10.0.0.2 6000 typ host\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n" +
+		"m=video 6002 UDP/TLS/RTP/SAVPF 96\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n")
+	answer, err := BundleAnswer(offer, local)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if mids, _ := answer.MIDs(); strings.Join(mids, " ") != "a v" {
+		t.Fatalf("medias not in the order of the offer: %v", mids)
+	}
+	audio, video := answer.Medias[0], answer.Medias[1]
+	if cs, _ := video.Candidates(); len(cs) != 1 {
+		t.Errorf("first media of the group: want 1 candidate, got %d", len(cs))
+	}
+	if cs, _ := audio.Candidates(); len(cs) != 0 {
+		t.Errorf("other media: unexpected candidates: %v", cs)
+	}
+	if audio.Port != 6002 {
+		t.Errorf("other media: want port 6002, got %d", audio.Port)
+	}
+}
+
+func TestRejectAll(t *testing.T) {
+	offer := MustParse("v=0\r\n" +
+		"o=alice 10 4 IN IP4 10.0.0.1\r\n" +
+		"s=call\r\n" +
+		"i=info\r\n" +
+		"c=IN IP4 10.0.0.1\r\n" +
+		"t=0 0\r\n" +
+		"t=3034540800 0\r\n" +
+		"a=group:BUNDLE a v\r\n" +
+		"m=audio 5000 RTP/AVP 0 8\r\na=mid:a\r\na=sendrecv\r\n" +
+		"m=video 5002 UDP/TLS/RTP/SAVPF 96\r\na=mid:v\r\na=rtpmap:96 VP8/90000\r\n" +
+		"m=application 5004 UDP/DTLS/SCTP webrtc-datachannel\r\n")
+	answer := RejectAll(offer)
+	if err := answer.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if s := answer.Session; s.ID != 10 || s.Ver != 5 || s.Name != "call" || s.Info != "" {
+		t.Errorf("unexpected origin: %+v", s)
+	}
+	if !answer.IsPermanent() {
+		t.Errorf("want a single t=0 0, got %v", answer.Intervals)
+	}
+	if len(answer.Attributes) != 0 {
+		t.Errorf("unexpected session attributes: %v", answer.Attributes)
+	}
+	if len(answer.Medias) != len(offer.Medias) {
+		t.Fatalf("want %d medias, got %d", len(offer.Medias), len(answer.Medias))
+	}
+	for i, m := range answer.Medias {
+		o := offer.Medias[i]
+		if m.Port != 0 || m.Media != o.Media || m.Proto != o.Proto {
+			t.Errorf("media #%d: not rejected: %s %d %s", i, m.Media, m.Port, m.Proto)
+		}
+		mid, _ := m.MID()
+		if want, _ := o.MID(); mid != want {
+			t.Errorf("media #%d: want mid %q, got %q", i, want, mid)
+		}
+	}
+	if _, err := ParseString(answer.Dump()); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,273 @@
+package sdp
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+type Option func(*options)
+
+// ParseOptions is the set of options given to ParseWith. It is built with
+// Options and can be reused for any number of calls.
+type ParseOptions struct {
+	options
+}
+
+// Options combines opts into a single value. Options are applied in order so
+// that the last one wins when several options set the same setting.
+func Options(opts ...Option) ParseOptions {
+	var o ParseOptions
+	for _, fn := range opts {
+		fn(&o.options)
+	}
+	return o
+}
+
+type options struct {
+	lenient      bool
+	trimValues   bool
+	rawMedia     bool
+	uppercase    bool
+	optionalName bool
+	maxMedias    int
+	validator    func(Scope, Attribute) error
+}
+
+// WithLenient relaxes the checks performed by Parse. By default, Parse runs in
+// strict mode and rejects input that does not follow RFC 4566.
+func WithLenient() Option {
+	return func(o *options) {
+		o.lenient = true
+	}
+}
+
+// WithTrimValues removes the space that some peers insert after the colon of
+// an attribute (eg: a=rtpmap: 96 H264/90000).
+func WithTrimValues() Option {
+	return func(o *options) {
+		o.trimValues = true
+	}
+}
+
+// WithRawMedia keeps the original text of each media block in MediaInfo.Raw.
+func WithRawMedia() Option {
+	return func(o *options) {
+		o.rawMedia = true
+	}
+}
+
+// WithUppercaseTokens accepts the net type, the address type and the well
+// known transport protocols whatever their case (eg: c=in ip4) and stores them
+// with their canonical case. Without it, these tokens are case-sensitive as
+// required by RFC 4566.
+func WithUppercaseTokens() Option {
+	return func(o *options) {
+		o.uppercase = true
+	}
+}
+
+// WithOptionalName accepts a session without s= line or with an empty one, as
+// found in skeletons of offers. The name of the session is then empty.
+func WithOptionalName() Option {
+	return func(o *options) {
+		o.optionalName = true
+	}
+}
+
+// WithMaxMedias makes Parse fail with ErrTooMany as soon as the input has
+// more than n medias.
+func WithMaxMedias(n int) Option {
+	return func(o *options) {
+		o.maxMedias = n
+	}
+}
+
+// WithAttributeValidator registers a function called for each attribute once
+// parsed. In strict mode, the first error returned by fn stops Parse. In
+// lenient mode, parsing continues and Parse returns all the errors once done.
+func WithAttributeValidator(fn func(Scope, Attribute) error) Option {
+	return func(o *options) {
+		o.validator = fn
+	}
+}
+
+// NewOption configures the session created by New.
+type NewOption func(*newOptions)
+
+type newOptions struct {
+	nextID func() int64
+}
+
+// WithSessionID sets the function used by New to get the id of the session.
+// By default, NextSessionID is used.
+func WithSessionID(fn func() int64) NewOption {
+	return func(o *newOptions) {
+		o.nextID = fn
+	}
+}
+
+// Span gives the position of a line in the input. Start and End are byte
+// offsets and End excludes the line terminator.
+type Span struct {
+	Prefix byte
+	Start  int
+	End    int
+}
+
+type reader struct {
+	*bufio.Reader
+	options
+
+	tracking bool
+	offset   int
+	spans    []Span
+
+	raw strings.Builder
+
+	errs errorList
+}
+
+func (r *reader) validate(scope Scope, a Attribute) error {
+	if r.validator == nil {
+		return nil
+	}
+	err := r.validator(scope, a)
+	if err == nil || !r.lenient {
+		return err
+	}
+	r.errs = append(r.errs, err)
+	return nil
+}
+
+var protos = []string{
+	"udp",
+	"RTP/AVP",
+	"RTP/AVPF",
+	"RTP/SAVP",
+	"RTP/SAVPF",
+	"UDP/TLS/RTP/SAVP",
+	"UDP/TLS/RTP/SAVPF",
+	"TCP/RTP/AVP",
+	"UDP/DTLS/SCTP",
+	"TCP/DTLS/SCTP",
+	"TCP/MSRP",
+	"TCP/TLS/MSRP",
+	"TCP/BFCP",
+	"TCP/TLS/BFCP",
+}
+
+// normalizeConn gives the canonical case to the net type and the address type
+// of the splitted address of a c= or o= line.
+func (r *reader) normalizeConn(parts []string) []string {
+	if !r.uppercase || len(parts) < 2 {
+		return parts
+	}
+	parts[0] = strings.ToUpper(parts[0])
+	parts[1] = strings.ToUpper(parts[1])
+	return parts
+}
+
+func (r *reader) normalizeProto(proto string) string {
+	if !r.uppercase {
+		return proto
+	}
+	for _, p := range protos {
+		if strings.EqualFold(p, proto) {
+			return p
+		}
+	}
+	return proto
+}
+
+// errorList gathers the errors found while parsing in lenient mode.
+type errorList []error
+
+func (e errorList) Error() string {
+	var msg []string
+	for _, err := range e {
+		msg = append(msg, err.Error())
+	}
+	return strings.Join(msg, "; ")
+}
+
+func (e errorList) Unwrap() []error {
+	return e
+}
+
+// Is and As make errors.Is and errors.As look at each error of the list with
+// versions of Go (before 1.20) that ignore Unwrap() []error.
+func (e errorList) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e errorList) As(target any) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// readLine reads the next line of input including its terminator. Lines can
+// be terminated by CRLF, LF or a lone CR. Like ReadString, io.EOF is returned
+// with the last line when it has no terminator.
+func (r *reader) readLine() (string, error) {
+	var line strings.Builder
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return line.String(), err
+		}
+		line.WriteByte(c)
+		switch c {
+		case '\n':
+			return line.String(), nil
+		case '\r':
+			if next, err := r.Peek(1); err == nil && next[0] == '\n' {
+				r.ReadByte()
+				line.WriteByte('\n')
+			}
+			return line.String(), nil
+		}
+	}
+}
+
+func (r *reader) capture() {
+	r.raw.Reset()
+}
+
+func (r *reader) captured() string {
+	return r.raw.String()
+}
+
+func (r *reader) record(line string) {
+	if r.rawMedia {
+		r.raw.WriteString(line)
+	}
+	if !r.tracking || line == "" {
+		return
+	}
+	s := Span{
+		Prefix: line[0],
+		Start:  r.offset,
+	}
+	r.offset += len(line)
+	s.End = s.Start + len(strings.TrimRight(line, "\r\n"))
+	r.spans = append(r.spans, s)
+}
+
+func newReader(r io.Reader, opts ParseOptions) *reader {
+	rs := reader{
+		Reader:  bufio.NewReader(r),
+		options: opts.options,
+	}
+	return &rs
+}
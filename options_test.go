@@ -0,0 +1,290 @@
+package sdp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type attrError struct {
+	Name string
+}
+
+func (e attrError) Error() string {
+	return fmt.Sprintf("attribute %s not allowed", e.Name)
+}
+
+func (e attrError) Unwrap() error {
+	return ErrInvalid
+}
+
+func TestParseAttributeValidator(t *testing.T) {
+	const str = "v=0\r\n" +
+		"o=- 1 1 IN IP4 1.2.3.4\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 1.2.3.4\r\n" +
+		"t=0 0\r\n" +
+		"a=x-secret:1\r\n" +
+		"m=audio 9 RTP/AVP 0\r\n" +
+		"a=x-secret:2\r\n" +
+		"a=sendrecv\r\n"
+
+	var calls int
+	validate := func(s Scope, a Attribute) error {
+		calls++
+		if a.Name == "x-secret" {
+			return fmt.Errorf("%s: %w", s, attrError{Name: a.Name})
+		}
+		return nil
+	}
+
+	_, err := ParseString(str, WithAttributeValidator(validate))
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("strict: expected %s, got %v", ErrInvalid, err)
+	}
+	if calls != 1 {
+		t.Errorf("strict: parsing not stopped at first error (%d calls)", calls)
+	}
+
+	calls = 0
+	f, err := ParseString(str, WithLenient(), WithAttributeValidator(validate))
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("lenient: expected %s, got %v", ErrInvalid, err)
+	}
+	if errors.Is(err, ErrSyntax) {
+		t.Errorf("lenient: unexpected error matched: %v", err)
+	}
+	var ae attrError
+	if !errors.As(err, &ae) || ae.Name != "x-secret" {
+		t.Errorf("lenient: attribute error not found in %v", err)
+	}
+	var list errorList
+	if !errors.As(err, &list) || len(list) != 2 {
+		t.Errorf("lenient: expected 2 errors, got %v", err)
+	}
+	if calls != 3 || len(f.Medias) != 1 {
+		t.Errorf("lenient: parsing stopped (%d calls, %d medias)", calls, len(f.Medias))
+	}
+}
+
+func TestParseLineEndings(t *testing.T) {
+	lines := []string{
+		"v=0",
+		"o=- 1 1 IN IP4 1.2.3.4",
+		"s=line endings",
+		"c=IN IP4 1.2.3.4",
+		"t=0 0",
+		"a=tool:",
+		"m=audio 9 RTP/AVP 0",
+		"a=sendrecv",
+	}
+	const tool = 5
+
+	var dumps []string
+	for _, eol := range []string{"\r\n", "\n", "\r"} {
+		f, err := ParseString(strings.Join(lines, eol) + eol)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %s", eol, err)
+		}
+		dumps = append(dumps, f.Dump())
+	}
+	if dumps[0] != dumps[1] || dumps[0] != dumps[2] {
+		t.Errorf("line endings give different results: %q", dumps)
+	}
+
+	// pad the tool attribute so that the first byte of its terminator is the
+	// last byte of the buffer of the reader (4096 bytes)
+	pad := func(eol string) []string {
+		arr := append([]string{}, lines...)
+		str := strings.Join(arr[:tool+1], eol)
+		arr[tool] += strings.Repeat("x", 4095-len(str))
+		return arr
+	}
+	for _, eol := range []string{"\r\n", "\n", "\r"} {
+		var (
+			arr  = pad(eol)
+			want = strings.Join(arr, "\r\n") + "\r\n"
+		)
+		for _, last := range []bool{true, false} {
+			str := strings.Join(arr, eol)
+			if last {
+				str += eol
+			}
+			if str[4095] != eol[0] {
+				t.Fatalf("%q: terminator not at buffer boundary", eol)
+			}
+			for _, r := range []io.Reader{strings.NewReader(str), iotest.OneByteReader(strings.NewReader(str))} {
+				f, err := Parse(r)
+				if err != nil {
+					t.Errorf("%q: unexpected error: %s", eol, err)
+					continue
+				}
+				if len(f.Medias) != 1 || !f.Medias[0].HasFlag("sendrecv") {
+					t.Errorf("%q (final terminator: %t): media not parsed", eol, last)
+				}
+				if got := f.Dump(); got != want {
+					t.Errorf("%q (final terminator: %t): dump mismatched", eol, last)
+				}
+			}
+		}
+	}
+}
+
+func TestParseTrimValues(t *testing.T) {
+	const str = "v=0\r\n" +
+		"o=- 1 1 IN IP4 1.2.3.4\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 1.2.3.4\r\n" +
+		"t=0 0\r\n" +
+		"m=video 5000 RTP/AVP 96\r\n" +
+		"a=rtpmap: 96 H264/90000\r\n" +
+		"a=fmtp: 96 packetization-mode=1\r\n"
+
+	f := MustParse(str)
+	if a := f.Medias[0].Attributes[0]; a.Value != " 96 H264/90000" {
+		t.Errorf("value modified without the option: %q", a.Value)
+	}
+	if _, err := f.Medias[0].Formats(); err == nil {
+		t.Errorf("expected error without the option")
+	}
+
+	f = MustParse(str, WithTrimValues())
+	rs, err := f.Medias[0].Formats()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(rs) != 1 || rs[0].Payload != 96 || rs[0].Encoding != "H264" || rs[0].ClockRate != 90000 {
+		t.Errorf("rtpmap: got %v", rs)
+	}
+	p, err := f.Medias[0].FormatParams(96)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v, _ := p.Get("packetization-mode"); v != "1" {
+		t.Errorf("fmtp: got %v", p)
+	}
+	if !strings.Contains(f.Dump(), "\r\na=rtpmap:96 H264/90000\r\n") {
+		t.Errorf("value not trimmed in output: %q", f.Dump())
+	}
+}
+
+func TestParseUppercaseTokens(t *testing.T) {
+	data := []struct {
+		Name  string
+		Input string
+	}{
+		{
+			Name: "c=",
+			Input: "v=0\r\n" +
+				"o=- 1 1 IN IP4 1.2.3.4\r\n" +
+				"s=-\r\n" +
+				"c=in ip4 1.2.3.4\r\n" +
+				"t=0 0\r\n" +
+				"m=audio 5000 rtp/avp 0\r\n" +
+				"c=In Ip4 1.2.3.5\r\n",
+		},
+		{
+			Name: "o=",
+			Input: "v=0\r\n" +
+				"o=- 1 1 in ip4 1.2.3.4\r\n" +
+				"s=-\r\n" +
+				"c=IN IP4 1.2.3.4\r\n" +
+				"t=0 0\r\n" +
+				"m=audio 5000 RTP/AVP 0\r\n" +
+				"c=IN IP4 1.2.3.5\r\n",
+		},
+	}
+	for _, d := range data {
+		if _, err := ParseString(d.Input); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: strict: expected %s, got %v", d.Name, ErrInvalid, err)
+		}
+		f, err := ParseString(d.Input, WithUppercaseTokens())
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Name, err)
+			continue
+		}
+		conns := []ConnInfo{f.Session.ConnInfo, f.ConnInfo, f.Medias[0].ConnInfo}
+		for _, c := range conns {
+			if c.NetType != NetTypeIN || c.AddrType != AddrType4 {
+				t.Errorf("%s: tokens not normalized: %s %s", d.Name, c.NetType, c.AddrType)
+			}
+		}
+		if f.Medias[0].Proto != "RTP/AVP" {
+			t.Errorf("%s: proto not normalized: %s", d.Name, f.Medias[0].Proto)
+		}
+		if str := f.Dump(); strings.Count(str, "IN IP4") != 3 {
+			t.Errorf("%s: tokens not written in upper case: %q", d.Name, str)
+		}
+	}
+}
+
+func TestParseOptionalName(t *testing.T) {
+	const str = "v=0\r\n" +
+		"o=- 1 1 IN IP4 1.2.3.4\r\n" +
+		"s=\r\n" +
+		"c=IN IP4 1.2.3.4\r\n" +
+		"t=0 0\r\n" +
+		"m=audio 5000 RTP/AVP 0\r\n"
+
+	if _, err := ParseString(str); !errors.Is(err, ErrSyntax) {
+		t.Errorf("expected %s, got %v", ErrSyntax, err)
+	}
+	f, err := ParseString(str, WithOptionalName())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if f.Session.Name != "" {
+		t.Errorf("want empty name, got %q", f.Session.Name)
+	}
+	if len(f.Medias) != 1 {
+		t.Errorf("want 1 media, got %d", len(f.Medias))
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	const str = "v=0\r\n" +
+		"o=- 1 1 in ip4 1.2.3.4\r\n" +
+		"s=\r\n" +
+		"c=IN IP4 1.2.3.4\r\n" +
+		"t=0 0\r\n" +
+		"m=video 5000 RTP/AVP 96\r\n" +
+		"a=rtpmap: 96 H264/90000\r\n" +
+		"m=audio 5002 RTP/AVP 0\r\n"
+
+	opts := Options(WithTrimValues(), WithUppercaseTokens(), WithOptionalName(), WithMaxMedias(1), WithMaxMedias(2))
+	for i := 0; i < 2; i++ {
+		f, err := ParseWith(strings.NewReader(str), opts)
+		if err != nil {
+			t.Fatalf("call #%d: unexpected error: %s", i, err)
+		}
+		if len(f.Medias) != 2 {
+			t.Errorf("call #%d: want 2 medias, got %d", i, len(f.Medias))
+		}
+		if f.Session.ConnInfo.NetType != NetTypeIN {
+			t.Errorf("call #%d: tokens not normalized", i)
+		}
+		if a := f.Medias[0].Attributes[0]; a.Value != "96 H264/90000" {
+			t.Errorf("call #%d: value not trimmed: %q", i, a.Value)
+		}
+	}
+
+	g, err := Parse(strings.NewReader(str), WithTrimValues(), WithUppercaseTokens(), WithOptionalName(), WithMaxMedias(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f, _ := ParseWith(strings.NewReader(str), opts)
+	if !f.Equal(g) {
+		t.Errorf("Parse and ParseWith mismatched")
+	}
+
+	opts = Options(WithTrimValues(), WithUppercaseTokens(), WithOptionalName(), WithMaxMedias(2), WithMaxMedias(1))
+	if _, err := ParseWith(strings.NewReader(str), opts); !errors.Is(err, ErrTooMany) {
+		t.Errorf("last option not applied: expected %s, got %v", ErrTooMany, err)
+	}
+	if _, err := ParseWith(strings.NewReader(str), Options()); err == nil {
+		t.Errorf("expected error with the default options")
+	}
+}
@@ -0,0 +1,87 @@
+package sdp
+
+// ParsedAttribute is implemented by the typed values of the attributes known
+// by this package. Attribute gives back the attribute it was parsed from, in
+// its canonical form.
+type ParsedAttribute interface {
+	Attribute() Attribute
+}
+
+// RawAttribute wraps the attributes that ParsedAttributes does not know.
+type RawAttribute Attribute
+
+func (r RawAttribute) Attribute() Attribute {
+	return Attribute(r)
+}
+
+func (r RTPMap) Attribute() Attribute {
+	return Attribute{Name: "rtpmap", Value: r.String()}
+}
+
+func (p FormatParams) Attribute() Attribute {
+	return Attribute{Name: "fmtp", Value: p.String()}
+}
+
+func (c Candidate) Attribute() Attribute {
+	return Attribute{Name: "candidate", Value: c.String()}
+}
+
+func (f Fingerprint) Attribute() Attribute {
+	return Attribute{Name: "fingerprint", Value: f.String()}
+}
+
+func (e ExtMap) Attribute() Attribute {
+	return Attribute{Name: "extmap", Value: e.String()}
+}
+
+func (g SSRCGroup) Attribute() Attribute {
+	return Attribute{Name: "ssrc-group", Value: g.String()}
+}
+
+func (a ImageAttr) Attribute() Attribute {
+	return Attribute{Name: "imageattr", Value: a.String()}
+}
+
+var attrparsers = map[string]func(string) (ParsedAttribute, error){
+	"rtpmap": func(str string) (ParsedAttribute, error) {
+		return parseRTPMap(str)
+	},
+	"fmtp": func(str string) (ParsedAttribute, error) {
+		return parseFormatParams(str)
+	},
+	"candidate": func(str string) (ParsedAttribute, error) {
+		return parseCandidate(str)
+	},
+	"fingerprint": func(str string) (ParsedAttribute, error) {
+		return parseFingerprint(str)
+	},
+	"extmap": func(str string) (ParsedAttribute, error) {
+		return parseExtMap(str)
+	},
+	"ssrc-group": func(str string) (ParsedAttribute, error) {
+		return parseSSRCGroup(str)
+	},
+	"imageattr": func(str string) (ParsedAttribute, error) {
+		return parseImageAttr(str)
+	},
+}
+
+// ParsedAttributes returns the attributes of m in their typed form: RTPMap,
+// FormatParams, Candidate, Fingerprint, ExtMap, SSRCGroup or ImageAttr. Any
+// other attribute is given as a RawAttribute.
+func (m MediaInfo) ParsedAttributes() ([]ParsedAttribute, error) {
+	var arr []ParsedAttribute
+	for _, a := range m.Attributes {
+		parse, ok := attrparsers[a.Name]
+		if !ok {
+			arr = append(arr, RawAttribute(a))
+			continue
+		}
+		p, err := parse(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, p)
+	}
+	return arr, nil
+}
@@ -0,0 +1,57 @@
+package sdp
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParsedAttributes(t *testing.T) {
+	m := MustParse(offerHead +
+		"m=video 9 UDP/TLS/RTP/SAVPF 96 97\r\n" +
+		"a=mid:v\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n" +
+		"a=fmtp:97 apt=96\r\n" +
+		"a=candidate:1 1 UDP 2130706431 ::1 5000 typ host\r\n" +
+		"a=fingerprint:sha-256 AB:CD:EF\r\n" +
+		"a=extmap:3 urn:ietf:params:rtp-hdrext:sdes:mid\r\n" +
+		"a=ssrc-group:FID 1 2\r\n" +
+		"a=imageattr:96 send [x=1280,y=720]\r\n" +
+		"a=rtcp-mux\r\n").Medias[0]
+	ps, err := m.ParsedAttributes()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []string{
+		"sdp.RawAttribute",
+		"sdp.RTPMap",
+		"sdp.FormatParams",
+		"sdp.Candidate",
+		"sdp.Fingerprint",
+		"sdp.ExtMap",
+		"sdp.SSRCGroup",
+		"sdp.ImageAttr",
+		"sdp.RawAttribute",
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("want %d attributes, got %d", len(want), len(ps))
+	}
+	for i, p := range ps {
+		if got := fmt.Sprintf("%T", p); got != want[i] {
+			t.Errorf("%d: want %s, got %s", i, want[i], got)
+		}
+		if a := p.Attribute(); a.Name != m.Attributes[i].Name {
+			t.Errorf("%d: want %s, got %s", i, m.Attributes[i].Name, a.Name)
+		}
+	}
+	if r, ok := ps[1].(RTPMap); !ok || r.Payload != 96 || r.Encoding != "VP8" {
+		t.Errorf("unexpected rtpmap: %v", ps[1])
+	}
+	if c, ok := ps[3].(Candidate); !ok || c.Addr != "::1" {
+		t.Errorf("unexpected candidate: %v", ps[3])
+	}
+
+	m.Attributes = append(m.Attributes, Attribute{Name: "rtpmap", Value: "x"})
+	if _, err := m.ParsedAttributes(); err == nil {
+		t.Errorf("expected error on invalid rtpmap")
+	}
+}
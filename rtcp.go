@@ -0,0 +1,123 @@
+package sdp
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Endpoint struct {
+	NetType  string
+	AddrType string
+	Addr     string
+	Port     uint16
+}
+
+func (e Endpoint) String() string {
+	return fmt.Sprintf("%s:%d", e.Addr, e.Port)
+}
+
+func endpointOf(c ConnInfo, port uint16) Endpoint {
+	return Endpoint{
+		NetType:  c.NetType,
+		AddrType: c.AddrType,
+		Addr:     c.Addr,
+		Port:     port,
+	}
+}
+
+// RTCP is the value of the rtcp attribute (RFC 3605). When the attribute only
+// gives a port, ConnInfo is inherited from the connection information of the
+// media (or of the session, see File.MediaRTCP) and Inherited is set.
+type RTCP struct {
+	Port      uint16
+	ConnInfo  ConnInfo
+	Inherited bool
+}
+
+func (m MediaInfo) RTCPMux() bool {
+	return m.HasFlag("rtcp-mux")
+}
+
+// RTCPExtendedReports returns the report blocks listed by the rtcp-xr
+// attributes of m (RFC 3611) as they appear (eg: pkt-loss-rle or
+// stat-summary=loss,dup). False is returned when m has no rtcp-xr attribute.
+func (m MediaInfo) RTCPExtendedReports() ([]string, bool) {
+	var (
+		arr []string
+		ok  bool
+	)
+	for _, a := range m.Attributes {
+		if a.Name != "rtcp-xr" {
+			continue
+		}
+		ok = true
+		arr = append(arr, strings.Fields(a.Value)...)
+	}
+	return arr, ok
+}
+
+func (m MediaInfo) RTCP() (RTCP, error) {
+	a, ok := findAttributes("rtcp", m.Attributes)
+	if !ok {
+		return RTCP{}, fmt.Errorf("rtcp not set")
+	}
+	r, err := parseRTCP(a.Value)
+	if err == nil && r.ConnInfo.IsZero() && !m.ConnInfo.IsZero() {
+		r.ConnInfo, r.Inherited = m.ConnInfo, true
+	}
+	return r, err
+}
+
+// MediaRTCP is like m.RTCP but the address also falls back to the connection
+// information of the session.
+func (f File) MediaRTCP(m MediaInfo) (RTCP, error) {
+	r, err := m.RTCP()
+	if err == nil && r.ConnInfo.IsZero() && !f.ConnInfo.IsZero() {
+		r.ConnInfo, r.Inherited = f.ConnInfo, true
+	}
+	return r, err
+}
+
+// RTCPEndpoint returns where RTCP packets of the media are sent. When rtcp-mux
+// is set, RTCP uses the same address and port as RTP. Otherwise, the rtcp
+// attribute gives the port (and optionally the address) to use. Finally, RTCP
+// defaults to the RTP port plus one.
+func (m MediaInfo) RTCPEndpoint(f File) (Endpoint, error) {
+	conn := m.ConnInfo
+	if conn.IsZero() {
+		conn = f.ConnInfo
+	}
+	if conn.IsZero() {
+		return Endpoint{}, fmt.Errorf("%w: media %s without connection information", ErrInvalid, m.Media)
+	}
+	if m.RTCPMux() {
+		return endpointOf(conn, m.Port), nil
+	}
+	if _, ok := findAttributes("rtcp", m.Attributes); ok {
+		r, err := f.MediaRTCP(m)
+		if err != nil {
+			return Endpoint{}, err
+		}
+		return endpointOf(r.ConnInfo, r.Port), nil
+	}
+	return endpointOf(conn, m.Port+1), nil
+}
+
+// rtcp:<port> [<nettype> <addrtype> <connection-address>]
+func parseRTCP(str string) (RTCP, error) {
+	var (
+		r     RTCP
+		parts = strings.Fields(str)
+		err   error
+	)
+	if len(parts) != 1 && len(parts) != 4 {
+		return r, fmt.Errorf("%w: rtcp (%s)", ErrSyntax, str)
+	}
+	if r.Port, err = parsePort(parts[0]); err != nil {
+		return r, err
+	}
+	if len(parts) == 4 {
+		r.ConnInfo, err = parseConnectionInfo(parts[1:])
+	}
+	return r, err
+}
@@ -0,0 +1,123 @@
+package sdp
+
+import "testing"
+
+func TestRTCPEndpoint(t *testing.T) {
+	data := []struct {
+		Name  string
+		Media string
+		Want  string
+	}{
+		{
+			Name:  "rtcp-mux",
+			Media: "m=audio 5000 RTP/AVP 0\r\na=rtcp:6000\r\na=rtcp-mux\r\n",
+			Want:  "10.0.0.1:5000",
+		},
+		{
+			Name:  "rtcp port",
+			Media: "m=audio 5000 RTP/AVP 0\r\na=rtcp:6000\r\n",
+			Want:  "10.0.0.1:6000",
+		},
+		{
+			Name:  "rtcp address",
+			Media: "m=audio 5000 RTP/AVP 0\r\na=rtcp:6000 IN IP4 10.0.0.9\r\n",
+			Want:  "10.0.0.9:6000",
+		},
+		{
+			Name:  "default",
+			Media: "m=audio 5000 RTP/AVP 0\r\nc=IN IP4 10.0.0.5\r\n",
+			Want:  "10.0.0.5:5001",
+		},
+	}
+	for _, d := range data {
+		f := MustParse(offerHead + d.Media)
+		e, err := f.Medias[0].RTCPEndpoint(f)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Name, err)
+			continue
+		}
+		if got := e.String(); got != d.Want {
+			t.Errorf("%s: want %s, got %s", d.Name, d.Want, got)
+		}
+	}
+	var f File
+	if _, err := (MediaInfo{Media: "audio", Port: 5000}).RTCPEndpoint(f); err == nil {
+		t.Errorf("expected error without connection information")
+	}
+}
+
+func TestMediaRTCP(t *testing.T) {
+	data := []struct {
+		Name      string
+		Media     string
+		Conn      ConnInfo
+		Inherited bool
+	}{
+		{
+			Name:      "session",
+			Media:     "m=audio 5000 RTP/AVP 0\r\na=rtcp:6000\r\n",
+			Conn:      IP4Conn("10.0.0.1"),
+			Inherited: true,
+		},
+		{
+			Name:      "media",
+			Media:     "m=audio 5000 RTP/AVP 0\r\nc=IN IP4 10.0.0.5\r\na=rtcp:6000\r\n",
+			Conn:      IP4Conn("10.0.0.5"),
+			Inherited: true,
+		},
+		{
+			Name:  "explicit",
+			Media: "m=audio 5000 RTP/AVP 0\r\nc=IN IP4 10.0.0.5\r\na=rtcp:6000 IN IP6 2001:db8::9\r\n",
+			Conn:  IP6Conn("2001:db8::9"),
+		},
+	}
+	for _, d := range data {
+		f := MustParse(offerHead + d.Media)
+		r, err := f.MediaRTCP(f.Medias[0])
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Name, err)
+			continue
+		}
+		if r.Port != 6000 {
+			t.Errorf("%s: want port 6000, got %d", d.Name, r.Port)
+		}
+		if r.ConnInfo != d.Conn || r.Inherited != d.Inherited {
+			t.Errorf("%s: want %s (inherited %t), got %s (inherited %t)", d.Name, d.Conn, d.Inherited, r.ConnInfo, r.Inherited)
+		}
+	}
+
+	f := MustParse(offerHead + "m=audio 5000 RTP/AVP 0\r\na=rtcp:6000\r\n")
+	r, err := f.Medias[0].RTCP()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !r.ConnInfo.IsZero() || r.Inherited {
+		t.Errorf("media without c=: address set to %s", r.ConnInfo)
+	}
+}
+
+func TestRTCPExtendedReports(t *testing.T) {
+	f := MustParse(offerHead +
+		"m=audio 5000 RTP/AVP 0\r\n" +
+		"a=rtcp-xr:pkt-loss-rle=100 rcvr-rtt=all:10 stat-summary=loss,dup,jitt\r\n" +
+		"a=ptime:20\r\n" +
+		"a=rtcp-xr:voip-metrics\r\n" +
+		"m=audio 5002 RTP/AVP 0\r\n")
+	xs, ok := f.Medias[0].RTCPExtendedReports()
+	want := []string{"pkt-loss-rle=100", "rcvr-rtt=all:10", "stat-summary=loss,dup,jitt", "voip-metrics"}
+	if !ok || len(xs) != len(want) {
+		t.Fatalf("want %d report blocks, got %v (%t)", len(want), xs, ok)
+	}
+	for i := range want {
+		if xs[i] != want[i] {
+			t.Errorf("block #%d: want %s, got %s", i, want[i], xs[i])
+		}
+	}
+	if xs, ok := f.Medias[1].RTCPExtendedReports(); ok || len(xs) != 0 {
+		t.Errorf("report blocks without rtcp-xr: %v", xs)
+	}
+	m := MediaInfo{Attributes: []Attribute{{Name: "rtcp-xr"}}}
+	if xs, ok := m.RTCPExtendedReports(); !ok || len(xs) != 0 {
+		t.Errorf("empty rtcp-xr: got %v (%t)", xs, ok)
+	}
+}
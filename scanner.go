@@ -0,0 +1,78 @@
+package sdp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Scanner reads a session description line by line without interpreting it.
+// Any type of line is accepted, known or not: only the <type>=<value> form of
+// each line is checked. Empty lines are skipped.
+type Scanner struct {
+	rs    *reader
+	typ   byte
+	value string
+	line  int
+	err   error
+}
+
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{
+		rs: newReader(r, Options()),
+	}
+}
+
+// Scan advances to the next line. It returns false at the end of the input or
+// when a line is malformed. Err gives the error, if any.
+func (s *Scanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	for {
+		line, err := s.rs.readLine()
+		if err != nil && err != io.EOF {
+			s.err = err
+			return false
+		}
+		if line == "" {
+			return false
+		}
+		s.line++
+		if line = strings.TrimRight(line, "\r\n"); line == "" {
+			continue
+		}
+		if len(line) < 2 || line[1] != '=' {
+			s.err = fmt.Errorf("%w: line %d: missing type (%s)", ErrSyntax, s.line, line)
+			return false
+		}
+		s.typ, s.value = line[0], line[2:]
+		return true
+	}
+}
+
+// Type returns the type of the current line (eg: 'a' for an attribute).
+func (s *Scanner) Type() byte {
+	return s.typ
+}
+
+// Value returns the current line without its type.
+func (s *Scanner) Value() string {
+	return s.value
+}
+
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// LineStats counts the lines of each type found in r.
+func LineStats(r io.Reader) (map[byte]int, error) {
+	var (
+		scan  = NewScanner(r)
+		stats = make(map[byte]int)
+	)
+	for scan.Scan() {
+		stats[scan.Type()]++
+	}
+	return stats, scan.Err()
+}
@@ -0,0 +1,45 @@
+package sdp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLineStats(t *testing.T) {
+	const str = sample + "z=2882844526 -1h 2898848070 0\r\n\r\nx=private\r\n"
+	stats, err := LineStats(strings.NewReader(str))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[byte]int{
+		'v': 1,
+		'o': 1,
+		's': 1,
+		'i': 1,
+		'u': 1,
+		'e': 1,
+		'c': 1,
+		't': 1,
+		'a': 2,
+		'm': 2,
+		'z': 1,
+		'x': 1,
+	}
+	if len(stats) != len(want) {
+		t.Errorf("want %d types, got %v", len(want), stats)
+	}
+	for k, n := range want {
+		if stats[k] != n {
+			t.Errorf("%c: want %d lines, got %d", k, n, stats[k])
+		}
+	}
+
+	stats, err = LineStats(strings.NewReader("v=0\r\nhello\r\ns=-\r\n"))
+	if !errors.Is(err, ErrSyntax) {
+		t.Errorf("expected %s, got %v", ErrSyntax, err)
+	}
+	if stats['v'] != 1 || stats['s'] != 0 {
+		t.Errorf("lines counted after the error: %v", stats)
+	}
+}
@@ -3,17 +3,31 @@ package sdp
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
 var (
 	ErrSyntax  = errors.New("syntax error")
 	ErrInvalid = errors.New("invalid")
+	ErrTooMany = fmt.Errorf("%w: too many elements", ErrInvalid)
+
+	ErrUnexpectedEOF = fmt.Errorf("%w: unexpected end of input", ErrSyntax)
+
+	// ErrFQDN is returned when an address is a domain name instead of an IP
+	// address. Callers may then fall back to a DNS lookup.
+	ErrFQDN = errors.New("address is a domain name")
 )
 
 const (
@@ -40,6 +54,35 @@ type Bandwidth struct {
 	Value int64
 }
 
+// IsExperimental reports whether b uses an experimental bandwidth type, ie a
+// type prefixed by "X-".
+func (b Bandwidth) IsExperimental() bool {
+	return strings.HasPrefix(b.Type, "X-")
+}
+
+// BitsPerSecond returns the value of b in bits per second. CT and AS are given
+// in kilobits per second while TIAS, RR and RS are already given in bits per
+// second. The value of other types is returned as is and false is returned.
+func (b Bandwidth) BitsPerSecond() (int64, bool) {
+	switch b.Type {
+	case "CT", "AS":
+		return b.Value * 1000, true
+	case "TIAS", "RR", "RS":
+		return b.Value, true
+	default:
+		return b.Value, false
+	}
+}
+
+func (b Bandwidth) isStandard() bool {
+	switch b.Type {
+	case "CT", "AS", "RR", "RS", "TIAS":
+		return true
+	default:
+		return false
+	}
+}
+
 type Attribute struct {
 	Name  string
 	Value string
@@ -59,12 +102,105 @@ type ConnInfo struct {
 	AddrType string
 	Addr     string
 	TTL      int64
+	Count    int64
+}
+
+// String returns c as written in a c= line.
+func (c ConnInfo) String() string {
+	var str strings.Builder
+	str.WriteString(c.NetType)
+	str.WriteByte(' ')
+	str.WriteString(c.AddrType)
+	str.WriteByte(' ')
+	str.WriteString(c.Addr)
+	if c.TTL > 0 {
+		str.WriteByte('/')
+		str.WriteString(strconv.FormatInt(c.TTL, 10))
+	}
+	if c.Count > 0 {
+		str.WriteByte('/')
+		str.WriteString(strconv.FormatInt(c.Count, 10))
+	}
+	return str.String()
+}
+
+// Equal reports whether c and o describe the same connection. IP addresses
+// are compared by value and domain names without regard to case.
+func (c ConnInfo) Equal(o ConnInfo) bool {
+	if c.NetType != o.NetType || c.AddrType != o.AddrType || c.TTL != o.TTL || c.Count != o.Count {
+		return false
+	}
+	a, err1 := netip.ParseAddr(c.Addr)
+	b, err2 := netip.ParseAddr(o.Addr)
+	if err1 == nil && err2 == nil {
+		return a == b
+	}
+	return strings.EqualFold(c.Addr, o.Addr)
 }
 
 func (c ConnInfo) IsZero() bool {
 	return c.NetType == "" && c.AddrType == "" && c.Addr == ""
 }
 
+// IP returns the address of c. ErrFQDN is returned if the address is not an IP
+// address but a domain name.
+func (c ConnInfo) IP() (netip.Addr, error) {
+	ip, err := netip.ParseAddr(c.Addr)
+	if err != nil {
+		return ip, fmt.Errorf("%w: %s", ErrFQDN, c.Addr)
+	}
+	if (c.AddrType == AddrType4 && !ip.Is4()) || (c.AddrType == AddrType6 && !ip.Is6()) {
+		return netip.Addr{}, fmt.Errorf("%w: %s: not an %s address", ErrInvalid, c.Addr, c.AddrType)
+	}
+	return ip, nil
+}
+
+func (c ConnInfo) IsMulticast() bool {
+	ip, err := c.IP()
+	return err == nil && ip.IsMulticast()
+}
+
+func IP4Conn(addr string) ConnInfo {
+	return ConnInfo{
+		NetType:  NetTypeIN,
+		AddrType: AddrType4,
+		Addr:     addr,
+	}
+}
+
+func IP6Conn(addr string) ConnInfo {
+	return ConnInfo{
+		NetType:  NetTypeIN,
+		AddrType: AddrType6,
+		Addr:     addr,
+	}
+}
+
+// Multicast4 returns the connection information of an IPv4 multicast group.
+// An error is returned if addr is not an IPv4 multicast address.
+func Multicast4(addr string, ttl int64) (ConnInfo, error) {
+	ip := net.ParseIP(addr)
+	if ip == nil || ip.To4() == nil || !ip.IsMulticast() {
+		return ConnInfo{}, fmt.Errorf("%w: %s: not an IPv4 multicast address", ErrInvalid, addr)
+	}
+	if ttl < 0 || ttl > 255 {
+		return ConnInfo{}, fmt.Errorf("%w: ttl out of range (%d)", ErrInvalid, ttl)
+	}
+	c := IP4Conn(addr)
+	c.TTL = ttl
+	return c, nil
+}
+
+// Multicast6 returns the connection information of an IPv6 multicast group.
+// An error is returned if addr is not an IPv6 multicast address.
+func Multicast6(addr string) (ConnInfo, error) {
+	ip := net.ParseIP(addr)
+	if ip == nil || ip.To4() != nil || !ip.IsMulticast() {
+		return ConnInfo{}, fmt.Errorf("%w: %s: not an IPv6 multicast address", ErrInvalid, addr)
+	}
+	return IP6Conn(addr), nil
+}
+
 type Session struct {
 	User string
 	ID   int64
@@ -76,6 +212,21 @@ type Session struct {
 	URI  string
 }
 
+// String returns the origin of the session as written in its o= line.
+func (s Session) String() string {
+	user := s.User
+	if user == "" {
+		user = "-"
+	}
+	return fmt.Sprintf("%s %d %d %s", user, s.ID, s.Ver, s.ConnInfo)
+}
+
+// HasUser reports whether the origin has a username. The dash used by the
+// origin line for an anonymous session is not a username.
+func (s Session) HasUser() bool {
+	return s.User != "" && s.User != "-"
+}
+
 type Interval struct {
 	Starts time.Time
 	Ends   time.Time
@@ -89,6 +240,45 @@ func (i Interval) IsPermanent() bool {
 	return i.Starts.IsZero() && i.Ends.IsZero()
 }
 
+const (
+	ntpEra       = 1 << 32
+	ntpThreshold = 1 << 31
+)
+
+// NTPToTime converts a NTP timestamp in seconds to a time. The zero
+// timestamp gives the zero time.
+//
+// 32 bit NTP timestamps wrap on 2036-02-07. To cope with this, a timestamp
+// below 2^31 (ie before 1968-01-20 in era 0) is considered to be in era 1.
+// Timestamps larger than 2^32 are used as is.
+func NTPToTime(n uint64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	if n < ntpThreshold {
+		n += ntpEra
+	}
+	return time.Unix(int64(n)-epoch, 0).UTC()
+}
+
+// TimeToNTP converts t to a NTP timestamp in seconds. The zero time gives the
+// zero timestamp.
+//
+// Times after the 2036 rollover are given relative to era 1 so that
+// NTPToTime gives back t for any time until 2104. Times before 1968-01-20
+// or after 2104-02-26 do not round-trip: NTPToTime places them in the other
+// era (eg: 1950-01-01 comes back as 2086-02-06).
+func TimeToNTP(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	n := uint64(t.Unix() + epoch)
+	if n >= ntpEra && n%ntpEra != 0 {
+		n %= ntpEra
+	}
+	return n
+}
+
 type SourceInfo struct {
 	Mode     string
 	NetType  string
@@ -101,10 +291,19 @@ func (s SourceInfo) Include() bool {
 	return s.Mode == ModeIncl
 }
 
+// String returns s as the value of a source-filter attribute:
+// <mode> <nettype> <addrtype> <dest> <src>...
+func (s SourceInfo) String() string {
+	parts := []string{s.Mode, s.NetType, s.AddrType, s.Addr}
+	return strings.Join(append(parts, s.List...), " ")
+}
+
+// source-filter: <mode> <nettype> <addrtype> <dest> <src>... (the value starts
+// with a space in RFC 4570 but it is also accepted without)
 func parseSourceInfo(line string) (SourceInfo, error) {
 	var (
-		parts = split(line)
-		size  = len(line)
+		parts = split(strings.TrimSpace(line))
+		size  = len(parts)
 		info  SourceInfo
 	)
 	if size < 5 {
@@ -139,6 +338,47 @@ type MediaInfo struct {
 	ConnInfo   ConnInfo
 	Bandwidth  []Bandwidth
 	Attributes []Attribute
+
+	// Raw is the original text of the media block. It is only set when
+	// parsing with WithRawMedia.
+	Raw   string
+	rawOf string
+}
+
+// String returns the media description of m as written in its m= line. The
+// other lines of the media are not included.
+func (m MediaInfo) String() string {
+	var str strings.Builder
+	str.WriteString(m.Media)
+	str.WriteByte(' ')
+	str.WriteString(strconv.FormatUint(uint64(m.Port), 10))
+	if m.Count > 0 {
+		str.WriteByte('/')
+		str.WriteString(strconv.FormatUint(uint64(m.Count), 10))
+	}
+	str.WriteByte(' ')
+	str.WriteString(m.Proto)
+	for i := range m.Attrs {
+		str.WriteByte(' ')
+		str.WriteString(m.Attrs[i])
+	}
+	return str.String()
+}
+
+func (m MediaInfo) dump() string {
+	var (
+		buf bytes.Buffer
+		ws  = bufio.NewWriter(&buf)
+	)
+	writeMediaInfo(ws, m)
+	ws.Flush()
+	return buf.String()
+}
+
+// hasRaw reports whether Raw can be used in place of m: it is the case as long
+// as m still serializes to the same text as when it was parsed.
+func (m MediaInfo) hasRaw() bool {
+	return m.Raw != "" && m.rawOf == m.dump()
 }
 
 func (m MediaInfo) PortRange() []uint16 {
@@ -152,6 +392,72 @@ func (m MediaInfo) PortRange() []uint16 {
 	return arr
 }
 
+// PortRangeSpan returns the first and the last port used by m without building
+// the list of ports given by PortRange.
+func (m MediaInfo) PortRangeSpan() (lo, hi uint16) {
+	if m.Count == 0 {
+		return m.Port, m.Port
+	}
+	last := int(m.Port) + int(m.Count) - 1
+	if last > math.MaxUint16 {
+		last = math.MaxUint16
+	}
+	return m.Port, uint16(last)
+}
+
+func (m MediaInfo) ContainsPort(p uint16) bool {
+	lo, hi := m.PortRangeSpan()
+	return p >= lo && p <= hi
+}
+
+func (m MediaInfo) ProtoParts() []string {
+	if m.Proto == "" {
+		return nil
+	}
+	return strings.Split(m.Proto, "/")
+}
+
+// UsesTLS reports whether the media is transported over TLS on top of a
+// stream transport (eg: TCP/TLS/RTP/AVP).
+func (m MediaInfo) UsesTLS() bool {
+	parts := m.ProtoParts()
+	for i := range parts {
+		if parts[i] == "TLS" && (i == 0 || parts[i-1] != "UDP") {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesDTLS reports whether the media is transported over DTLS. As defined by
+// RFC 5764, UDP/TLS means DTLS.
+func (m MediaInfo) UsesDTLS() bool {
+	parts := m.ProtoParts()
+	for i := range parts {
+		if parts[i] == "DTLS" || (parts[i] == "TLS" && i > 0 && parts[i-1] == "UDP") {
+			return true
+		}
+	}
+	return false
+}
+
+func (m MediaInfo) UsesRTP() bool {
+	return m.hasProtoPart("RTP")
+}
+
+func (m MediaInfo) UsesSCTP() bool {
+	return m.hasProtoPart("SCTP")
+}
+
+func (m MediaInfo) hasProtoPart(part string) bool {
+	for _, p := range m.ProtoParts() {
+		if p == part {
+			return true
+		}
+	}
+	return false
+}
+
 func (m MediaInfo) SourceFilter() (SourceInfo, error) {
 	a, ok := findAttributes("source-filter", m.Attributes)
 	if !ok {
@@ -160,6 +466,19 @@ func (m MediaInfo) SourceFilter() (SourceInfo, error) {
 	return parseSourceInfo(a.Value)
 }
 
+// SetSourceFilter replaces the source-filter of m by s. An error is returned,
+// and m left unchanged, when the mode, the network type or the address type
+// of s are invalid or when s has no source. As in RFC 4570, the value written
+// is preceded by a space.
+func (m *MediaInfo) SetSourceFilter(s SourceInfo) error {
+	if _, err := parseSourceInfo(s.String()); err != nil {
+		return err
+	}
+	m.Attributes, _ = removeAttributes(m.Attributes, "source-filter")
+	m.Attributes = append(m.Attributes, Attribute{Name: "source-filter", Value: " " + s.String()})
+	return nil
+}
+
 type File struct {
 	Version int
 	Session
@@ -174,18 +493,91 @@ type File struct {
 	Intervals []Interval
 
 	Medias []MediaInfo
+
+	// SyntheticTiming is set when the input has no t= line and a permanent
+	// interval has been added by Parse in lenient mode.
+	SyntheticTiming bool
+
+	// ContentBase is the base URL given by the Content-Base header of the RTSP
+	// response the session comes from (see ParseRTSP). It is never written.
+	ContentBase string
 }
 
+// Dump returns f in its textual form. The result is empty when f can not be
+// serialized: use WriteTo (or String) to know why.
 func (f File) Dump() string {
 	var buf bytes.Buffer
 	f.DumpTo(&buf)
 	return buf.String()
 }
 
+// DumpRaw is like Dump but writes the original text of the media that have
+// been parsed with WithRawMedia and not modified since.
+func (f File) DumpRaw() string {
+	if err := checkText(f); err != nil {
+		return ""
+	}
+	var (
+		buf bytes.Buffer
+		ws  = bufio.NewWriter(&buf)
+	)
+	writeFile(ws, f, true)
+	ws.Flush()
+	return buf.String()
+}
+
 func (f File) DumpTo(w io.Writer) {
-	ws := bufio.NewWriter(w)
-	defer ws.Flush()
+	f.WriteTo(w)
+}
+
+// WriteTo writes f to w. Nothing is written and an error wrapping ErrInvalid
+// is returned if a field of f contains a character that would break the line
+// structure of the output (NUL, CR or LF).
+func (f File) WriteTo(w io.Writer) (int64, error) {
+	return DefaultDumpOptions().WriteTo(w, f)
+}
+
+// DumpFile writes f to the named file. The content is first written to a
+// temporary file in the same directory which is then renamed, so that the
+// named file is never left truncated.
+func DumpFile(name string, f File) error {
+	perm := os.FileMode(0644)
+	if fi, err := os.Stat(name); err == nil {
+		perm = fi.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
 
+	if _, err = f.WriteTo(tmp); err == nil {
+		err = tmp.Sync()
+	}
+	if e := tmp.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+type countWriter struct {
+	io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(b []byte) (int, error) {
+	n, err := w.Writer.Write(b)
+	w.n += int64(n)
+	return n, err
+}
+
+func writeFile(ws *bufio.Writer, f File, raw bool) {
 	writePrefix(ws, 'v')
 	ws.WriteString(strconv.Itoa(f.Version))
 	writeEOL(ws)
@@ -198,15 +590,165 @@ func (f File) DumpTo(w io.Writer) {
 		writePrefix(ws, 'p')
 		writeLine(ws, f.Phone[i])
 	}
-	writeConnInfo(ws, f.ConnInfo, true)
+	writeConnInfo(ws, f.ConnInfo)
 	writeBandwidths(ws, f.Bandwidth)
-	writeAttributes(ws, f.Attributes)
 	writeIntervals(ws, f.Intervals)
+	writeAttributes(ws, f.Attributes)
 	for i := range f.Medias {
+		if raw && f.Medias[i].hasRaw() {
+			writeRaw(ws, f.Medias[i].Raw)
+			continue
+		}
 		writeMediaInfo(ws, f.Medias[i])
 	}
 }
 
+// String returns f in its textual form (see Dump). Unlike Dump, the reason
+// why f can not be serialized is returned instead of an empty string, so the
+// fault is not hidden in logs.
+func (f File) String() string {
+	var str strings.Builder
+	if _, err := f.WriteTo(&str); err != nil {
+		return fmt.Sprintf("sdp: %s", err)
+	}
+	return str.String()
+}
+
+// Equal reports whether f and o have the same textual form. A File that can
+// not be serialized (see WriteTo) is not equal to any other.
+func (f File) Equal(o File) bool {
+	var a, b bytes.Buffer
+	if _, err := f.WriteTo(&a); err != nil {
+		return false
+	}
+	if _, err := o.WriteTo(&b); err != nil {
+		return false
+	}
+	return bytes.Equal(a.Bytes(), b.Bytes())
+}
+
+var sessionID = int64(TimeToNTP(time.Now()))
+
+// NextSessionID returns a new session id. Ids are taken from a counter seeded
+// with the current NTP time so that two calls never return the same id.
+func NextSessionID() int64 {
+	return atomic.AddInt64(&sessionID, 1)
+}
+
+// New creates a session with the given name originated from addr. addr is also
+// the connection information of the session used by the medias without their
+// own. The id of the session is given by NextSessionID unless another
+// generator is set with WithSessionID. The session is permanent (t=0 0).
+func New(name string, addr ConnInfo, opts ...NewOption) File {
+	o := newOptions{
+		nextID: NextSessionID,
+	}
+	for _, fn := range opts {
+		fn(&o)
+	}
+	f := File{
+		Session: Session{
+			ID:       o.nextID(),
+			Ver:      1,
+			ConnInfo: addr,
+			Name:     name,
+		},
+		ConnInfo:  addr,
+		Intervals: []Interval{{}},
+	}
+	return f
+}
+
+// MakePermanent replaces the intervals of f by a single permanent interval
+// (t=0 0). Repeat times and time zones are not kept by Parse so there is
+// nothing else to clear.
+func (f *File) MakePermanent() {
+	f.Intervals = []Interval{{}}
+}
+
+// IsPermanent reports whether the only interval of f is permanent.
+func (f File) IsPermanent() bool {
+	return len(f.Intervals) == 1 && f.Intervals[0].IsPermanent()
+}
+
+// BumpVersion increments the version of the origin, as required each time a
+// modified session is sent.
+func (f *File) BumpVersion() {
+	f.Session.Ver++
+}
+
+// AddMedia appends m to the medias of f. An error is returned if neither m
+// nor the session has connection information.
+func (f *File) AddMedia(m MediaInfo) error {
+	if m.ConnInfo.IsZero() && f.ConnInfo.IsZero() {
+		return fmt.Errorf("%w: media %s without connection information", ErrInvalid, m.Media)
+	}
+	f.Medias = append(f.Medias, m)
+	return nil
+}
+
+// TotalBandwidth sums, in bits per second (see Bandwidth.BitsPerSecond), the
+// bandwidth of the given type of all the medias of f that are not rejected. A
+// media without bandwidth of this type uses the one of the session.
+func (f File) TotalBandwidth(typ string) int64 {
+	find := func(bws []Bandwidth) (int64, bool) {
+		for _, b := range bws {
+			if b.Type == typ {
+				n, _ := b.BitsPerSecond()
+				return n, true
+			}
+		}
+		return 0, false
+	}
+	var (
+		total      int64
+		session, _ = find(f.Bandwidth)
+	)
+	for _, m := range f.Medias {
+		if m.Port == 0 {
+			continue
+		}
+		if n, ok := find(m.Bandwidth); ok {
+			total += n
+		} else {
+			total += session
+		}
+	}
+	return total
+}
+
+// MediaContext is the description of a media once the values inherited from
+// the session are resolved.
+type MediaContext struct {
+	ConnInfo  ConnInfo
+	Bandwidth []Bandwidth
+	Direction Direction
+}
+
+// EachMedia calls fn for each media of f with its resolved context: the
+// connection information and the bandwidths of the session are used for a
+// media that does not define its own. The iteration stops at the first error
+// returned by fn.
+func (f File) EachMedia(fn func(i int, m MediaInfo, ctx MediaContext) error) error {
+	for i, m := range f.Medias {
+		ctx := MediaContext{
+			ConnInfo:  m.ConnInfo,
+			Bandwidth: m.Bandwidth,
+			Direction: f.EffectiveDirection(m),
+		}
+		if ctx.ConnInfo.IsZero() {
+			ctx.ConnInfo = f.ConnInfo
+		}
+		if len(ctx.Bandwidth) == 0 {
+			ctx.Bandwidth = f.Bandwidth
+		}
+		if err := fn(i, m, ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (f File) Types() []string {
 	var arr []string
 	for i := range f.Medias {
@@ -215,6 +757,21 @@ func (f File) Types() []string {
 	return arr
 }
 
+// AddressFamilies returns the address types used by the connection
+// information of the session and of its medias.
+func (f File) AddressFamilies() map[string]bool {
+	set := make(map[string]bool)
+	if !f.ConnInfo.IsZero() {
+		set[f.ConnInfo.AddrType] = true
+	}
+	for i := range f.Medias {
+		if c := f.Medias[i].ConnInfo; !c.IsZero() {
+			set[c.AddrType] = true
+		}
+	}
+	return set
+}
+
 func (f File) SourceFilter() (SourceInfo, error) {
 	a, ok := findAttributes("source-filter", f.Attributes)
 	if !ok {
@@ -223,11 +780,45 @@ func (f File) SourceFilter() (SourceInfo, error) {
 	return parseSourceInfo(a.Value)
 }
 
-func Parse(r io.Reader) (File, error) {
-	var (
-		rs   = bufio.NewReader(r)
-		file File
-	)
+// Parse reads a session description from r.
+//
+// When an error occurs, the returned File holds everything parsed before the
+// faulty line: previous lines and medias are kept while the media being parsed
+// when the error occurs is dropped.
+func Parse(r io.Reader, opts ...Option) (File, error) {
+	return ParseWith(r, Options(opts...))
+}
+
+// ParseWith parses r like Parse with a set of options built by Options.
+func ParseWith(r io.Reader, opts ParseOptions) (File, error) {
+	return parse(newReader(r, opts))
+}
+
+func ParseString(str string, opts ...Option) (File, error) {
+	return Parse(strings.NewReader(str), opts...)
+}
+
+// MustParse is like ParseString but panics if str can not be parsed. It is
+// intended for tests and for the initialization of package level variables.
+func MustParse(str string, opts ...Option) File {
+	f, err := ParseString(str, opts...)
+	if err != nil {
+		panic("sdp: MustParse: " + err.Error())
+	}
+	return f
+}
+
+// ParseWithSpans parses r like Parse and also returns the position of each
+// line in the input.
+func ParseWithSpans(r io.Reader, opts ...Option) (File, []Span, error) {
+	rs := newReader(r, Options(opts...))
+	rs.tracking = true
+	f, err := parse(rs)
+	return f, rs.spans, err
+}
+
+func parse(rs *reader) (File, error) {
+	var file File
 	for i := range parsers {
 		p := parsers[i]
 		if err := p.parse(&file, rs, p.prefix); err != nil {
@@ -237,12 +828,50 @@ func Parse(r io.Reader) (File, error) {
 			return file, err
 		}
 	}
+	if len(file.Intervals) == 0 && rs.lenient {
+		file.Intervals = append(file.Intervals, Interval{})
+		file.SyntheticTiming = true
+	}
+	if len(rs.errs) > 0 {
+		return file, rs.errs
+	}
 	return file, nil
 }
 
+// ParseFile opens and parses the named file. Errors returned by os.Open are
+// returned as is while parse errors are prefixed with the name of the file.
+func ParseFile(name string, opts ...Option) (File, error) {
+	r, err := os.Open(name)
+	if err != nil {
+		return File{}, err
+	}
+	defer r.Close()
+
+	f, err := Parse(r, opts...)
+	if err != nil {
+		err = fmt.Errorf("%s: %w", name, err)
+	}
+	return f, err
+}
+
+// ParseReader parses r like Parse but transparently decompresses it first
+// when it is gzip compressed.
+func ParseReader(r io.Reader, opts ...Option) (File, error) {
+	rs := bufio.NewReader(r)
+	if magic, _ := rs.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		z, err := gzip.NewReader(rs)
+		if err != nil {
+			return File{}, err
+		}
+		defer z.Close()
+		return Parse(z, opts...)
+	}
+	return Parse(rs, opts...)
+}
+
 var parsers = []struct {
 	prefix string
-	parse  func(*File, *bufio.Reader, string) error
+	parse  func(*File, *reader, string) error
 }{
 	{prefix: "v", parse: parseVersion},
 	{prefix: "o", parse: parseOrigin},
@@ -262,7 +891,7 @@ var parsers = []struct {
 
 var mediaparsers = []struct {
 	prefix string
-	parse  func(*MediaInfo, *bufio.Reader, string) error
+	parse  func(*MediaInfo, *reader, string) error
 }{
 	{prefix: "i", parse: parseMediaInfo},
 	{prefix: "c", parse: parseMediaConnInfo},
@@ -270,11 +899,15 @@ var mediaparsers = []struct {
 	{prefix: "a", parse: parseMediaAttributes},
 }
 
-func parseMedia(file *File, rs *bufio.Reader, prefix string) error {
+func parseMedia(file *File, rs *reader, prefix string) error {
 	for {
 		if !hasPrefix(rs, prefix) {
 			break
 		}
+		if rs.maxMedias > 0 && len(file.Medias) >= rs.maxMedias {
+			return fmt.Errorf("%w: more than %d medias", ErrTooMany, rs.maxMedias)
+		}
+		rs.capture()
 		line, err := checkLine(rs, prefix)
 		if err != nil {
 			return err
@@ -283,12 +916,16 @@ func parseMedia(file *File, rs *bufio.Reader, prefix string) error {
 		if err != nil {
 			return err
 		}
+		if rs.rawMedia {
+			mi.Raw = rs.captured()
+			mi.rawOf = mi.dump()
+		}
 		file.Medias = append(file.Medias, mi)
 	}
 	return nil
 }
 
-func parseMediaDescription(line string, rs *bufio.Reader) (MediaInfo, error) {
+func parseMediaDescription(line string, rs *reader) (MediaInfo, error) {
 	var (
 		mi    MediaInfo
 		err   error
@@ -298,24 +935,22 @@ func parseMediaDescription(line string, rs *bufio.Reader) (MediaInfo, error) {
 		return mi, ErrSyntax
 	}
 	mi.Media = parts[0]
-	if x := strings.Index(parts[1], "/"); x > 0 {
-		var n uint64
-		if n, err = strconv.ParseUint(parts[1][x:], 10, 16); err != nil {
-			return mi, err
-		}
-		mi.Port = uint16(n)
-		if n, err = strconv.ParseUint(parts[1][x+1:], 10, 16); err != nil {
-			return mi, err
+	port, count, ok := strings.Cut(parts[1], "/")
+	n, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return mi, fmt.Errorf("%w: invalid media port %q", ErrSyntax, port)
+	}
+	mi.Port = uint16(n)
+	if ok {
+		if n, err = strconv.ParseUint(count, 10, 16); err != nil {
+			return mi, fmt.Errorf("%w: invalid media port count %q", ErrSyntax, count)
 		}
 		mi.Count = uint16(n)
-	} else {
-		n, err := strconv.ParseUint(parts[1], 10, 16)
-		if err != nil {
-			return mi, err
-		}
-		mi.Port = uint16(n)
 	}
-	mi.Proto = parts[2]
+	if mi.Port == 0 && mi.Count > 0 {
+		return mi, fmt.Errorf("%w: port count (%d) set on a rejected media", ErrInvalid, mi.Count)
+	}
+	mi.Proto = rs.normalizeProto(parts[2])
 	mi.Attrs = append(mi.Attrs, parts[3:]...)
 	for i := range mediaparsers {
 		p := mediaparsers[i]
@@ -326,13 +961,13 @@ func parseMediaDescription(line string, rs *bufio.Reader) (MediaInfo, error) {
 	return mi, nil
 }
 
-func parseInterval(file *File, rs *bufio.Reader, prefix string) error {
+func parseInterval(file *File, rs *reader, prefix string) error {
 	parse := func(str string) (time.Time, error) {
-		n, err := strconv.ParseInt(str, 10, 64)
-		if err != nil || n == 0 {
+		n, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
 			return time.Time{}, err
 		}
-		return time.Unix(n-epoch, 0).UTC(), nil
+		return NTPToTime(n), nil
 	}
 	for {
 		if !hasPrefix(rs, prefix) {
@@ -358,90 +993,117 @@ func parseInterval(file *File, rs *bufio.Reader, prefix string) error {
 	return nil
 }
 
-func parseAttributes(file *File, rs *bufio.Reader, prefix string) error {
+func parseAttributes(file *File, rs *reader, prefix string) error {
 	var err error
-	file.Attributes, err = parseAttributeLines(rs, prefix)
+	file.Attributes, err = parseAttributeLines(rs, prefix, ScopeSession)
 	return err
 }
 
-func parseMediaAttributes(media *MediaInfo, rs *bufio.Reader, prefix string) error {
+func parseMediaAttributes(media *MediaInfo, rs *reader, prefix string) error {
 	var err error
-	media.Attributes, err = parseAttributeLines(rs, prefix)
+	if media.Attributes, err = parseAttributeLines(rs, prefix, ScopeMedia); err != nil {
+		return err
+	}
+	if rs.lenient {
+		return nil
+	}
+	if mid, ok := media.MID(); ok {
+		err = validToken(mid)
+	}
+	if err == nil {
+		_, err = media.ExtMaps()
+	}
 	return err
 }
 
-func parseBandwidth(file *File, rs *bufio.Reader, prefix string) error {
+func parseBandwidth(file *File, rs *reader, prefix string) error {
 	var err error
 	file.Bandwidth, err = parseBandwidthLines(rs, prefix)
 	return err
 }
 
-func parseMediaBandwidth(media *MediaInfo, rs *bufio.Reader, prefix string) error {
+func parseMediaBandwidth(media *MediaInfo, rs *reader, prefix string) error {
 	var err error
 	media.Bandwidth, err = parseBandwidthLines(rs, prefix)
 	return err
 }
 
-func parseConnInfo(file *File, rs *bufio.Reader, prefix string) error {
+func parseConnInfo(file *File, rs *reader, prefix string) error {
 	line, err := setString(rs, prefix, false)
 	if err != nil || line == "" {
 		return err
 	}
-	file.ConnInfo, err = parseConnectionInfo(split(line))
+	file.ConnInfo, err = parseConnectionInfo(rs.normalizeConn(split(line)))
 	return err
 }
 
-func parseMediaConnInfo(media *MediaInfo, rs *bufio.Reader, prefix string) error {
+func parseMediaConnInfo(media *MediaInfo, rs *reader, prefix string) error {
 	line, err := setString(rs, prefix, false)
 	if err != nil || line == "" {
 		return err
 	}
-	media.ConnInfo, err = parseConnectionInfo(split(line))
+	media.ConnInfo, err = parseConnectionInfo(rs.normalizeConn(split(line)))
 	return err
 }
 
-func parsePhone(file *File, rs *bufio.Reader, prefix string) error {
+func parsePhone(file *File, rs *reader, prefix string) error {
 	var err error
 	file.Phone, err = setArray(rs, prefix)
 	return err
 }
 
-func parseEmail(file *File, rs *bufio.Reader, prefix string) error {
+func parseEmail(file *File, rs *reader, prefix string) error {
 	var err error
 	file.Email, err = setArray(rs, prefix)
 	return err
 }
 
-func parseURI(file *File, rs *bufio.Reader, prefix string) error {
+func parseURI(file *File, rs *reader, prefix string) error {
 	var err error
-	file.Session.URI, err = setString(rs, prefix, false)
-	return err
+	if file.Session.URI, err = setString(rs, prefix, false); err != nil {
+		return err
+	}
+	return checkDuplicate(rs, prefix)
 }
 
-func parseInfo(file *File, rs *bufio.Reader, prefix string) error {
+func parseInfo(file *File, rs *reader, prefix string) error {
 	var err error
-	file.Session.Info, err = setString(rs, prefix, false)
-	return err
+	if file.Session.Info, err = setString(rs, prefix, false); err != nil {
+		return err
+	}
+	return checkDuplicate(rs, prefix)
 }
 
-func parseMediaInfo(media *MediaInfo, rs *bufio.Reader, prefix string) error {
+func parseMediaInfo(media *MediaInfo, rs *reader, prefix string) error {
 	var err error
 	media.Info, err = setString(rs, prefix, false)
 	return err
 }
 
-func parseName(file *File, rs *bufio.Reader, prefix string) error {
-	var err error
-	file.Session.Name, err = setString(rs, prefix, true)
-	if err == nil && file.Session.Name == "" {
-		err = fmt.Errorf("empty session name")
+func parseName(file *File, rs *reader, prefix string) error {
+	// with WithOptionalName, the s= line can be missing but, when present, it
+	// is checked for truncation like any required line
+	required := !rs.optionalName || hasPrefix(rs, prefix)
+	name, err := setString(rs, prefix, required)
+	if err != nil {
+		return err
 	}
-	return err
+	// RFC 4566 recommends "s= " for sessions without meaningful name: blank
+	// names are only replaced by an empty name in lenient mode, other names
+	// are kept as is
+	switch {
+	case (rs.lenient || rs.optionalName) && strings.TrimSpace(name) == "":
+		name = ""
+	case name == "":
+		return fmt.Errorf("%w: empty session name", ErrSyntax)
+	}
+	file.Session.Name = name
+	return checkDuplicate(rs, prefix)
 }
 
 // o=<username> <sess-id> <sess-version> <nettype> <addrtype> <unicast-address>
-func parseOrigin(file *File, rs *bufio.Reader, prefix string) error {
-	line, err := checkLine(rs, prefix)
+func parseOrigin(file *File, rs *reader, prefix string) error {
+	line, err := checkRequiredLine(rs, prefix)
 	if err != nil {
 		return err
 	}
@@ -458,8 +1120,10 @@ func parseOrigin(file *File, rs *bufio.Reader, prefix string) error {
 	if file.Session.Ver, err = strconv.ParseInt(parts[2], 10, 64); err != nil {
 		return fmt.Errorf("%w - session version: %s", ErrSyntax, err)
 	}
-	file.Session.ConnInfo, err = parseConnectionInfo(parts[3:])
-	return err
+	if file.Session.ConnInfo, err = parseConnectionInfo(rs.normalizeConn(parts[3:])); err != nil {
+		return err
+	}
+	return checkDuplicate(rs, prefix)
 }
 
 func parseConnectionInfo(parts []string) (ConnInfo, error) {
@@ -477,17 +1141,34 @@ func parseConnectionInfo(parts []string) (ConnInfo, error) {
 	ci.AddrType = parts[1]
 	ci.Addr = parts[2]
 	if x := strings.Index(ci.Addr, "/"); x > 0 {
-		var err error
-		if ci.TTL, err = strconv.ParseInt(ci.Addr[x+1:], 10, 16); err != nil {
-			return ci, err
-		}
+		var (
+			err  error
+			nums = strings.Split(ci.Addr[x+1:], "/")
+		)
 		ci.Addr = ci.Addr[:x]
+		// IP4 multicast addresses have a TTL and an optional count while
+		// IP6 addresses only have a count
+		if ci.AddrType == AddrType4 {
+			if ci.TTL, err = strconv.ParseInt(nums[0], 10, 16); err != nil {
+				return ci, err
+			}
+			nums = nums[1:]
+		}
+		switch len(nums) {
+		case 0:
+		case 1:
+			if ci.Count, err = strconv.ParseInt(nums[0], 10, 16); err != nil {
+				return ci, err
+			}
+		default:
+			return ci, fmt.Errorf("%w: connection address (%s)", ErrSyntax, parts[2])
+		}
 	}
 	return ci, nil
 }
 
-func parseVersion(file *File, rs *bufio.Reader, prefix string) error {
-	line, err := checkLine(rs, prefix)
+func parseVersion(file *File, rs *reader, prefix string) error {
+	line, err := checkRequiredLine(rs, prefix)
 	if err != nil {
 		return err
 	}
@@ -495,10 +1176,13 @@ func parseVersion(file *File, rs *bufio.Reader, prefix string) error {
 	if file.Version != 0 {
 		return fmt.Errorf("%w: unsupported version", ErrInvalid)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	return checkDuplicate(rs, prefix)
 }
 
-func skip(_ *File, rs *bufio.Reader, prefix string) error {
+func skip(_ *File, rs *reader, prefix string) error {
 	for {
 		if !hasPrefix(rs, prefix) {
 			break
@@ -511,29 +1195,32 @@ func skip(_ *File, rs *bufio.Reader, prefix string) error {
 	return nil
 }
 
-func parseAttributeLines(rs *bufio.Reader, prefix string) ([]Attribute, error) {
-	var (
-		arr []Attribute
-		atb Attribute
-	)
+func parseAttributeLines(rs *reader, prefix string, scope Scope) ([]Attribute, error) {
+	var arr []Attribute
 	for hasPrefix(rs, prefix) {
 		line, err := checkLine(rs, prefix)
 		if err != nil {
-			return nil, err
+			return arr, err
 		}
-		x := strings.Index(line, ":")
-		if x < 0 {
+		var atb Attribute
+		if x := strings.Index(line, ":"); x < 0 {
 			atb.Name = line
-			continue
+		} else {
+			atb.Name = line[:x]
+			atb.Value = line[x+1:]
+			if rs.trimValues {
+				atb.Value = strings.TrimPrefix(atb.Value, " ")
+			}
+		}
+		if err := rs.validate(scope, atb); err != nil {
+			return arr, err
 		}
-		atb.Name = line[:x]
-		atb.Value = line[x+1:]
 		arr = append(arr, atb)
 	}
 	return arr, nil
 }
 
-func parseBandwidthLines(rs *bufio.Reader, prefix string) ([]Bandwidth, error) {
+func parseBandwidthLines(rs *reader, prefix string) ([]Bandwidth, error) {
 	var (
 		arr []Bandwidth
 		bwd Bandwidth
@@ -541,15 +1228,18 @@ func parseBandwidthLines(rs *bufio.Reader, prefix string) ([]Bandwidth, error) {
 	for hasPrefix(rs, prefix) {
 		line, err := checkLine(rs, prefix)
 		if err != nil {
-			return nil, err
+			return arr, err
 		}
 		x := strings.Index(line, ":")
 		if x <= 0 || x >= len(line)-1 {
-			return nil, fmt.Errorf("%w: parsing bandwidth (%s)", ErrSyntax, line)
+			return arr, fmt.Errorf("%w: parsing bandwidth (%s)", ErrSyntax, line)
 		}
 		bwd.Type = line[:x]
+		if !rs.lenient && !bwd.isStandard() && !bwd.IsExperimental() {
+			return arr, fmt.Errorf("%w: unknown bandwidth type %s", ErrInvalid, bwd.Type)
+		}
 		if bwd.Value, err = strconv.ParseInt(line[x+1:], 10, 64); err != nil {
-			return nil, err
+			return arr, err
 		}
 		arr = append(arr, bwd)
 	}
@@ -560,14 +1250,32 @@ func split(line string) []string {
 	return strings.Split(line, " ")
 }
 
-func setString(rs *bufio.Reader, prefix string, required bool) (string, error) {
-	if !required && !hasPrefix(rs, prefix) {
-		return "", nil
+// checkDuplicate reports an error wrapping ErrInvalid when the line identified
+// by prefix appears more than once. In lenient mode, the extra lines are
+// dropped and the first one is kept.
+func checkDuplicate(rs *reader, prefix string) error {
+	for hasPrefix(rs, prefix) {
+		if !rs.lenient {
+			return fmt.Errorf("%w: duplicate %s= line", ErrInvalid, prefix)
+		}
+		if _, err := checkLine(rs, prefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setString(rs *reader, prefix string, required bool) (string, error) {
+	if !required {
+		if !hasPrefix(rs, prefix) {
+			return "", nil
+		}
+		return checkLine(rs, prefix)
 	}
-	return checkLine(rs, prefix)
+	return checkRequiredLine(rs, prefix)
 }
 
-func setArray(rs *bufio.Reader, prefix string) ([]string, error) {
+func setArray(rs *reader, prefix string) ([]string, error) {
 	var arr []string
 	for {
 		if !hasPrefix(rs, prefix) {
@@ -575,36 +1283,61 @@ func setArray(rs *bufio.Reader, prefix string) ([]string, error) {
 		}
 		line, err := checkLine(rs, prefix)
 		if err != nil {
-			return nil, err
+			return arr, err
 		}
 		arr = append(arr, line)
 	}
 	return arr, nil
 }
 
-func hasPrefix(rs *bufio.Reader, prefix string) bool {
+func hasPrefix(rs *reader, prefix string) bool {
 	peek, _ := rs.Peek(len(prefix))
 	return string(peek) == prefix
 }
 
-func checkLine(rs *bufio.Reader, prefix string) (string, error) {
-	line, err := rs.ReadString('\n')
+func checkLine(rs *reader, prefix string) (string, error) {
+	line, _, err := readLine(rs, prefix)
+	return line, err
+}
+
+// checkRequiredLine is like checkLine but it also fails with ErrUnexpectedEOF
+// when the line is the last of the input since a required line is always
+// followed by other lines.
+func checkRequiredLine(rs *reader, prefix string) (string, error) {
+	line, eof, err := readLine(rs, prefix)
+	if err == nil && eof {
+		err = fmt.Errorf("%w: %s= line truncated", ErrUnexpectedEOF, prefix)
+	}
+	return line, err
+}
+
+func readLine(rs *reader, prefix string) (string, bool, error) {
+	line, err := rs.readLine()
 	if err != nil && !errors.Is(err, io.EOF) {
-		return "", err
+		return "", false, err
 	}
+	eof := err != nil
+	rs.record(line)
 	line = strings.TrimRight(line, "\r\n")
 	prefix += "="
 	if !strings.HasPrefix(line, prefix) {
-		return "", fmt.Errorf("%w: missing prefix %s", ErrSyntax, prefix)
+		if eof && strings.HasPrefix(prefix, line) {
+			return "", eof, fmt.Errorf("%w: missing %s line", ErrUnexpectedEOF, prefix)
+		}
+		return "", eof, fmt.Errorf("%w: missing prefix %s", ErrSyntax, prefix)
 	}
-	return line[len(prefix):], nil
+	// NUL is not allowed in text fields and it would be refused by WriteTo
+	if strings.IndexByte(line, 0) >= 0 {
+		return "", eof, fmt.Errorf("%w: illegal character NUL in %s line", ErrSyntax, prefix)
+	}
+	return line[len(prefix):], eof, nil
 }
 
 func validAddrType(str string, star bool) error {
 	switch str {
 	case AddrType4, AddrType6:
 	default:
-		if !star && str != "*" {
+		if !star || str != "*" {
 			return fmt.Errorf("%w: unknown addr type %s", ErrInvalid, str)
 		}
 	}
@@ -625,12 +1358,83 @@ func validModeType(str string) error {
 	return fmt.Errorf("%w: unknown mode type %s", ErrInvalid, str)
 }
 
+func validToken(str string) error {
+	if str == "" {
+		return fmt.Errorf("%w: empty token", ErrInvalid)
+	}
+	for i := 0; i < len(str); i++ {
+		switch c := str[i]; {
+		case c == 0x21, c >= 0x23 && c <= 0x27, c == 0x2a, c == 0x2b, c == 0x2d, c == 0x2e:
+		case c >= 0x30 && c <= 0x39, c >= 0x41 && c <= 0x5a, c >= 0x5e && c <= 0x7e:
+		default:
+			return fmt.Errorf("%w: invalid character in token %q", ErrInvalid, str)
+		}
+	}
+	return nil
+}
+
+// checkText verifies that the fields of f can be written without injecting
+// extra lines in the output.
+func checkText(f File) error {
+	var err error
+	check := func(field, str string) {
+		if err != nil {
+			return
+		}
+		if x := strings.IndexAny(str, "\x00\r\n"); x >= 0 {
+			err = fmt.Errorf("%w: illegal character %q in %s", ErrInvalid, str[x], field)
+		}
+	}
+	checkConn := func(field string, c ConnInfo) {
+		check(field, c.NetType)
+		check(field, c.AddrType)
+		check(field, c.Addr)
+	}
+	checkAttrs := func(attrs []Attribute) {
+		for _, a := range attrs {
+			check("attribute", a.Name)
+			check("attribute", a.Value)
+			if err == nil && strings.Contains(a.Name, ":") {
+				err = fmt.Errorf("%w: illegal character ':' in attribute name %s", ErrInvalid, a.Name)
+			}
+		}
+	}
+	checkBands := func(bws []Bandwidth) {
+		for _, b := range bws {
+			check("bandwidth", b.Type)
+		}
+	}
+	check("origin", f.Session.User)
+	checkConn("origin", f.Session.ConnInfo)
+	check("session name", f.Session.Name)
+	check("session info", f.Session.Info)
+	check("uri", f.Session.URI)
+	for _, e := range f.Email {
+		check("email", e)
+	}
+	for _, p := range f.Phone {
+		check("phone", p)
+	}
+	checkConn("connection", f.ConnInfo)
+	checkBands(f.Bandwidth)
+	checkAttrs(f.Attributes)
+	for _, m := range f.Medias {
+		check("media", m.Media)
+		check("media", m.Proto)
+		for _, a := range m.Attrs {
+			check("media", a)
+		}
+		check("media info", m.Info)
+		checkConn("connection", m.ConnInfo)
+		checkBands(m.Bandwidth)
+		checkAttrs(m.Attributes)
+	}
+	return err
+}
+
 func writeIntervals(w *bufio.Writer, is []Interval) {
 	convert := func(t time.Time) string {
-		if t.IsZero() {
-			return "0"
-		}
-		return strconv.FormatInt(t.Unix()+epoch, 10)
+		return strconv.FormatUint(TimeToNTP(t), 10)
 	}
 	for i := range is {
 		writePrefix(w, 't')
@@ -643,18 +1447,13 @@ func writeIntervals(w *bufio.Writer, is []Interval) {
 
 func writeSession(w *bufio.Writer, sess Session) {
 	writePrefix(w, 'o')
-	if sess.User == "" {
-		sess.User = "-"
-	}
-	w.WriteString(sess.User)
-	w.WriteByte(' ')
-	w.WriteString(strconv.FormatInt(sess.ID, 10))
-	w.WriteByte(' ')
-	w.WriteString(strconv.FormatInt(sess.Ver, 10))
-	w.WriteByte(' ')
-	writeConnInfo(w, sess.ConnInfo, false)
+	w.WriteString(sess.String())
+	writeEOL(w)
 
 	writePrefix(w, 's')
+	if sess.Name == "" {
+		sess.Name = "-"
+	}
 	writeLine(w, sess.Name)
 	if sess.Info != "" {
 		writePrefix(w, 'i')
@@ -668,45 +1467,23 @@ func writeSession(w *bufio.Writer, sess Session) {
 
 func writeMediaInfo(w *bufio.Writer, m MediaInfo) {
 	writePrefix(w, 'm')
-	w.WriteString(m.Media)
-	w.WriteByte(' ')
-	w.WriteString(strconv.FormatUint(uint64(m.Port), 10))
-	if m.Count > 0 {
-		w.WriteByte('/')
-		w.WriteString(strconv.FormatUint(uint64(m.Count), 10))
-	}
-	w.WriteByte(' ')
-	w.WriteString(m.Proto)
-	for i := range m.Attrs {
-		w.WriteByte(' ')
-		w.WriteString(m.Attrs[i])
-	}
+	w.WriteString(m.String())
 	writeEOL(w)
 	if m.Info != "" {
 		writePrefix(w, 'i')
 		writeLine(w, m.Info)
 	}
-	writeConnInfo(w, m.ConnInfo, true)
+	writeConnInfo(w, m.ConnInfo)
 	writeBandwidths(w, m.Bandwidth)
 	writeAttributes(w, m.Attributes)
 }
 
-func writeConnInfo(w *bufio.Writer, conn ConnInfo, prefix bool) {
+func writeConnInfo(w *bufio.Writer, conn ConnInfo) {
 	if conn.IsZero() {
 		return
 	}
-	if prefix {
-		writePrefix(w, 'c')
-	}
-	w.WriteString(conn.NetType)
-	w.WriteByte(' ')
-	w.WriteString(conn.AddrType)
-	w.WriteByte(' ')
-	w.WriteString(conn.Addr)
-	if conn.TTL > 0 {
-		w.WriteByte('/')
-		w.WriteString(strconv.FormatInt(conn.TTL, 10))
-	}
+	writePrefix(w, 'c')
+	w.WriteString(conn.String())
 	writeEOL(w)
 }
 
@@ -724,8 +1501,10 @@ func writeAttributes(w *bufio.Writer, attrs []Attribute) {
 	for i := range attrs {
 		writePrefix(w, 'a')
 		w.WriteString(attrs[i].Name)
-		w.WriteByte(':')
-		w.WriteString(attrs[i].Value)
+		if attrs[i].Value != "" {
+			w.WriteByte(':')
+			w.WriteString(attrs[i].Value)
+		}
 		writeEOL(w)
 	}
 }
@@ -735,6 +1514,13 @@ func writePrefix(w *bufio.Writer, prefix byte) {
 	w.WriteByte('=')
 }
 
+func writeRaw(w *bufio.Writer, raw string) {
+	w.WriteString(raw)
+	if !strings.HasSuffix(raw, "\n") {
+		writeEOL(w)
+	}
+}
+
 func writeLine(w *bufio.Writer, line string) {
 	w.WriteString(line)
 	writeEOL(w)
@@ -0,0 +1,1161 @@
+package sdp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sample = "v=0\r\n" +
+	"o=jdoe 2890844526 2890842807 IN IP4 10.47.16.5\r\n" +
+	"s=SDP Seminar\r\n" +
+	"i=A Seminar on the session description protocol\r\n" +
+	"u=http://www.example.com/seminars/sdp.pdf\r\n" +
+	"e=j.doe@example.com (Jane Doe)\r\n" +
+	"c=IN IP4 224.2.17.12/127\r\n" +
+	"t=2873397496 2873404696\r\n" +
+	"a=recvonly\r\n" +
+	"m=audio 49170 RTP/AVP 0\r\n" +
+	"m=video 51372 RTP/AVP 99\r\n" +
+	"a=rtpmap:99 h263-1998/90000\r\n"
+
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		sample,
+		"v=0\r\no=- 1 1 IN IP4 127.0.0.1\r\ns=-\r\nt=0 0\r\n",
+		"v=0\no=- 1 1 IN IP6 ::1\ns=-\nc=IN IP6 ff15::101/3\nt=0 0\nm=audio 9/2 RTP/AVP 0 8\n",
+		"v=0\ro=- 1 1 IN IP4 1.2.3.4\rs=x\rc=IN IP4 1.2.3.4\rt=0 0\ra=group:BUNDLE a\rm=audio 9 UDP/TLS/RTP/SAVPF 111\ra=mid:a\ra=rtpmap:111 opus/48000/2\r",
+		"v=0\r\no=- 1 1 IN IP4 1.2.3.4\r\ns=x\r\nc=IN IP4 1.2.3.4\r\nb=AS:128\r\nt=0 0\r\nm=audio 0 RTP/AVP 0\r\na=source-filter: incl IN IP4 * 10.0.0.1\r\n",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, str string) {
+		file, err := ParseString(str)
+		if err != nil {
+			return
+		}
+		dump := file.Dump()
+		again, err := ParseString(dump)
+		if err != nil {
+			t.Fatalf("parse of dump failed: %s\n%q", err, dump)
+		}
+		if got := again.Dump(); got != dump {
+			t.Fatalf("dumps mismatched:\nwant: %q\ngot:  %q", dump, got)
+		}
+	})
+}
+
+func TestParseMaxMedias(t *testing.T) {
+	head := "v=0\r\no=- 1 1 IN IP4 1.2.3.4\r\ns=-\r\nc=IN IP4 1.2.3.4\r\nt=0 0\r\n"
+	data := []struct {
+		Medias int
+		Max    int
+		Err    error
+	}{
+		{Medias: 0, Max: 0},
+		{Medias: 5, Max: 0},
+		{Medias: 2, Max: 3},
+		{Medias: 3, Max: 3},
+		{Medias: 4, Max: 3, Err: ErrTooMany},
+		{Medias: 2, Max: 1, Err: ErrTooMany},
+	}
+	for _, d := range data {
+		str := head + strings.Repeat("m=audio 9 RTP/AVP 0\r\n", d.Medias)
+		f, err := ParseString(str, WithMaxMedias(d.Max))
+		if d.Err != nil {
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%d medias (max %d): expected %s, got %v", d.Medias, d.Max, d.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d medias (max %d): unexpected error: %s", d.Medias, d.Max, err)
+			continue
+		}
+		if len(f.Medias) != d.Medias {
+			t.Errorf("%d medias (max %d): got %d medias", d.Medias, d.Max, len(f.Medias))
+		}
+	}
+}
+
+func TestDumpFile(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		name = filepath.Join(dir, "session.sdp")
+		orig = []byte(sample)
+	)
+	if err := os.WriteFile(name, orig, 0600); err != nil {
+		t.Fatal(err)
+	}
+	f := MustParse(sample)
+	f.Session.Name = "bad\nname"
+	if err := DumpFile(name, f); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected %s, got %v", ErrInvalid, err)
+	}
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, orig) {
+		t.Errorf("original file modified: %q", got)
+	}
+	es, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 1 {
+		t.Errorf("temporary file left in directory: %d files", len(es))
+	}
+
+	f.Session.Name = "SDP Update"
+	if err := DumpFile(name, f); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, _ := os.ReadFile(name); string(got) != f.Dump() {
+		t.Errorf("file not updated: %q", got)
+	}
+	if fi, err := os.Stat(name); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("permissions not kept: %v", fi)
+	}
+}
+
+func TestParseMediaPort(t *testing.T) {
+	data := []struct {
+		Input string
+		Port  uint16
+		Count uint16
+		Err   error
+	}{
+		{Input: "9", Port: 9},
+		{Input: "49170/2", Port: 49170, Count: 2},
+		{Input: "1000/65000", Port: 1000, Count: 65000},
+		{Input: "abc", Err: ErrSyntax},
+		{Input: "9/x", Err: ErrSyntax},
+		{Input: "70000", Err: ErrSyntax},
+		{Input: "/2", Err: ErrSyntax},
+		{Input: "0/2", Err: ErrInvalid},
+	}
+	head := "v=0\r\no=- 1 1 IN IP4 1.2.3.4\r\ns=-\r\nc=IN IP4 1.2.3.4\r\nt=0 0\r\n"
+	for _, d := range data {
+		f, err := ParseString(head + "m=audio " + d.Input + " RTP/AVP 0\r\n")
+		if d.Err != nil {
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%s: expected %s, got %v", d.Input, d.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		m := f.Medias[0]
+		if m.Port != d.Port || m.Count != d.Count {
+			t.Errorf("%s: want %d/%d, got %d/%d", d.Input, d.Port, d.Count, m.Port, m.Count)
+		}
+	}
+}
+
+func TestContainsPort(t *testing.T) {
+	m := MediaInfo{Port: 49170, Count: 4}
+	for _, p := range m.PortRange() {
+		if !m.ContainsPort(p) {
+			t.Errorf("port %d not in range", p)
+		}
+	}
+	for _, p := range []uint16{0, 49169, 49174, 65535} {
+		if m.ContainsPort(p) {
+			t.Errorf("port %d in range", p)
+		}
+	}
+	m = MediaInfo{Port: 65534, Count: 10}
+	if lo, hi := m.PortRangeSpan(); lo != 65534 || hi != 65535 {
+		t.Errorf("span not capped: %d-%d", lo, hi)
+	}
+}
+
+var benchMedia = MustParse("v=0\r\no=- 1 1 IN IP4 1.2.3.4\r\ns=-\r\nc=IN IP4 1.2.3.4\r\nt=0 0\r\nm=audio 1000/65000 RTP/AVP 0\r\n").Medias[0]
+
+func BenchmarkContainsPort(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchMedia.ContainsPort(uint16(i))
+	}
+}
+
+func BenchmarkPortRange(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := uint16(i)
+		for _, x := range benchMedia.PortRange() {
+			if x == p {
+				break
+			}
+		}
+	}
+}
+
+func TestWriteInjectedLines(t *testing.T) {
+	data := []struct {
+		Name   string
+		Modify func(*File)
+	}{
+		{
+			Name:   "session attribute",
+			Modify: func(f *File) { f.Attributes = append(f.Attributes, Attribute{Name: "tool", Value: "x\r\na=evil"}) },
+		},
+		{
+			Name:   "media attribute",
+			Modify: func(f *File) { f.Medias[0].Attributes = append(f.Medias[0].Attributes, Attribute{Name: "x\na=evil"}) },
+		},
+		{
+			Name:   "session name",
+			Modify: func(f *File) { f.Session.Name = "x\r\na=evil" },
+		},
+		{
+			Name:   "email",
+			Modify: func(f *File) { f.Email = append(f.Email, "x@example.com\rm=audio 9 RTP/AVP 0") },
+		},
+		{
+			Name:   "connection address",
+			Modify: func(f *File) { f.ConnInfo.Addr = "224.2.17.12\r\na=evil" },
+		},
+		{
+			Name:   "nul",
+			Modify: func(f *File) { f.Session.Info = "x\x00" },
+		},
+	}
+	for _, d := range data {
+		f := MustParse(sample, WithRawMedia())
+		d.Modify(&f)
+
+		var buf bytes.Buffer
+		n, err := f.WriteTo(&buf)
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected %s, got %v", d.Name, ErrInvalid, err)
+		}
+		if n != 0 || buf.Len() != 0 {
+			t.Errorf("%s: %d bytes written", d.Name, buf.Len())
+		}
+		if str := f.Dump(); str != "" {
+			t.Errorf("%s: Dump: %q", d.Name, str)
+		}
+		if str := f.DumpRaw(); str != "" {
+			t.Errorf("%s: DumpRaw: %q", d.Name, str)
+		}
+		buf.Reset()
+		opts := DumpOptions{OmitOptional: true}
+		if _, err := opts.WriteTo(&buf, f); !errors.Is(err, ErrInvalid) || buf.Len() != 0 {
+			t.Errorf("%s: DumpOptions.WriteTo: %d bytes written (%v)", d.Name, buf.Len(), err)
+		}
+		if str := f.String(); !strings.HasPrefix(str, "sdp: ") || strings.Contains(str, "\n") {
+			t.Errorf("%s: String: %q", d.Name, str)
+		}
+	}
+}
+
+func TestFileEqual(t *testing.T) {
+	a, b := MustParse(sample), MustParse(sample)
+	if !a.Equal(b) {
+		t.Errorf("same files not equal")
+	}
+	b.Session.Name = "other"
+	if a.Equal(b) {
+		t.Errorf("different files equal")
+	}
+	a.Session.Name = "a\r\na=x"
+	b.Session.Name = "totally\ndifferent"
+	if a.Equal(b) || a.Equal(a) {
+		t.Errorf("invalid files equal")
+	}
+}
+
+func TestConnInfoString(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  ConnInfo
+		Err   error
+	}{
+		{
+			Input: "IN IP4 10.0.0.1",
+			Want:  ConnInfo{NetType: "IN", AddrType: "IP4", Addr: "10.0.0.1"},
+		},
+		{
+			Input: "IN IP4 224.2.1.1/127",
+			Want:  ConnInfo{NetType: "IN", AddrType: "IP4", Addr: "224.2.1.1", TTL: 127},
+		},
+		{
+			Input: "IN IP4 224.2.1.1/127/3",
+			Want:  ConnInfo{NetType: "IN", AddrType: "IP4", Addr: "224.2.1.1", TTL: 127, Count: 3},
+		},
+		{
+			Input: "IN IP6 ff15::101/3",
+			Want:  ConnInfo{NetType: "IN", AddrType: "IP6", Addr: "ff15::101", Count: 3},
+		},
+		{
+			Input: "IN IP6 ff15::101/3/2",
+			Err:   ErrSyntax,
+		},
+		{
+			Input: "IN IP4 224.2.1.1/127/3/2",
+			Err:   ErrSyntax,
+		},
+	}
+	for _, d := range data {
+		c, err := parseConnectionInfo(strings.Fields(d.Input))
+		if d.Err != nil {
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%s: expected %s, got %v", d.Input, d.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		if c != d.Want {
+			t.Errorf("%s: want %+v, got %+v", d.Input, d.Want, c)
+		}
+		if str := c.String(); str != d.Input {
+			t.Errorf("%s: String: got %s", d.Input, str)
+		}
+	}
+}
+
+func TestParseSessionName(t *testing.T) {
+	data := []struct {
+		Line    string
+		Options []Option
+		Name    string
+		Dump    string
+		Err     error
+	}{
+		{Line: "s=\r\n", Err: ErrSyntax},
+		{Line: "s= \r\n", Name: " ", Dump: "s= \r\n"},
+		{Line: "s=  x \r\n", Name: "  x ", Dump: "s=  x \r\n"},
+		{Line: "s=\r\n", Options: []Option{WithLenient()}, Dump: "s=-\r\n"},
+		{Line: "s=   \r\n", Options: []Option{WithLenient()}, Dump: "s=-\r\n"},
+		{Line: "s=  x \r\n", Options: []Option{WithLenient()}, Name: "  x ", Dump: "s=  x \r\n"},
+		{Line: "s=\t\r\n", Options: []Option{WithOptionalName()}, Dump: "s=-\r\n"},
+		{Line: "s=  x \r\n", Options: []Option{WithOptionalName()}, Name: "  x ", Dump: "s=  x \r\n"},
+		{Line: "", Options: []Option{WithOptionalName()}, Dump: "s=-\r\n"},
+	}
+	for _, d := range data {
+		str := "v=0\r\no=- 1 1 IN IP4 1.2.3.4\r\n" + d.Line + "t=0 0\r\n"
+		f, err := ParseString(str, d.Options...)
+		if d.Err != nil {
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%q: expected %s, got %v", d.Line, d.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", d.Line, err)
+			continue
+		}
+		if f.Session.Name != d.Name {
+			t.Errorf("%q: want name %q, got %q", d.Line, d.Name, f.Session.Name)
+		}
+		if dump := f.Dump(); !strings.Contains(dump, "\r\n"+d.Dump) {
+			t.Errorf("%q: want %q in %q", d.Line, d.Dump, dump)
+		}
+	}
+}
+
+func TestNTPToTime(t *testing.T) {
+	data := []struct {
+		NTP  uint64
+		Want string
+	}{
+		{NTP: 1<<32 - 1, Want: "2036-02-07T06:28:15Z"},
+		{NTP: 1 << 32, Want: "2036-02-07T06:28:16Z"},
+		{NTP: 1, Want: "2036-02-07T06:28:17Z"},
+		{NTP: 1<<31 - 1, Want: "2104-02-26T09:42:23Z"},
+		{NTP: 1 << 31, Want: "1968-01-20T03:14:08Z"},
+		{NTP: 3155673600, Want: "2000-01-01T00:00:00Z"},
+	}
+	for _, d := range data {
+		got := NTPToTime(d.NTP)
+		if str := got.Format(time.RFC3339); str != d.Want {
+			t.Errorf("%d: want %s, got %s", d.NTP, d.Want, str)
+		}
+		if n := TimeToNTP(got); NTPToTime(n) != got {
+			t.Errorf("%d: %s does not round-trip (%d)", d.NTP, d.Want, n)
+		}
+	}
+	if n := NTPToTime(0); !n.IsZero() || TimeToNTP(n) != 0 {
+		t.Errorf("zero time not kept: %s", n)
+	}
+	old := time.Date(1950, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := NTPToTime(TimeToNTP(old)); got.Year() != 2086 {
+		t.Errorf("time before 1968 expected in era 1, got %s", got)
+	}
+}
+
+func TestParseTruncated(t *testing.T) {
+	const (
+		v = "v=0"
+		o = "o=- 1 1 IN IP4 1.2.3.4"
+		s = "s=x"
+	)
+	data := []struct {
+		Input   string
+		Options []Option
+		Err     error
+	}{
+		{Input: "", Err: ErrUnexpectedEOF},
+		{Input: "v=", Err: ErrUnexpectedEOF},
+		{Input: v, Err: ErrUnexpectedEOF},
+		{Input: v + "\r\n", Err: ErrUnexpectedEOF},
+		{Input: v + "\r\no=-", Err: ErrUnexpectedEOF},
+		{Input: v + "\r\n" + o, Err: ErrUnexpectedEOF},
+		{Input: v + "\r\n" + o + "\r\n", Err: ErrUnexpectedEOF},
+		{Input: v + "\r\n" + o + "\r\n" + s, Err: ErrUnexpectedEOF},
+		{Input: v + "\r\n" + o + "\r\n" + s, Options: []Option{WithLenient()}, Err: ErrUnexpectedEOF},
+		{Input: v + "\r\n" + o + "\r\n" + s + "\r\n"},
+		// the s= line is optional: an input ending after the o= line is
+		// accepted but an s= line ending the input is still truncated
+		{Input: v + "\r\n" + o, Options: []Option{WithOptionalName()}, Err: ErrUnexpectedEOF},
+		{Input: v + "\r\n" + o + "\r\n", Options: []Option{WithOptionalName()}},
+		{Input: v + "\r\n" + o + "\r\n" + s, Options: []Option{WithOptionalName()}, Err: ErrUnexpectedEOF},
+	}
+	for _, d := range data {
+		f, err := ParseString(d.Input, d.Options...)
+		if d.Err != nil {
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%q: expected %s, got %v", d.Input, d.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", d.Input, err)
+			continue
+		}
+		if f.Session.ID != 1 {
+			t.Errorf("%q: origin not parsed", d.Input)
+		}
+	}
+}
+
+func TestDumpOrder(t *testing.T) {
+	f := File{
+		Session: Session{
+			ID:       1,
+			Ver:      1,
+			Name:     "order",
+			Info:     "info",
+			URI:      "http://example.com",
+			ConnInfo: IP4Conn("1.2.3.4"),
+		},
+		Email:      []string{"j.doe@example.com"},
+		Phone:      []string{"+1 617 555-6011"},
+		ConnInfo:   IP4Conn("1.2.3.4"),
+		Bandwidth:  []Bandwidth{{Type: "AS", Value: 128}},
+		Attributes: []Attribute{{Name: "recvonly"}},
+		Intervals:  []Interval{{}},
+		Medias: []MediaInfo{
+			{
+				Media:      "audio",
+				Port:       49170,
+				Proto:      "RTP/AVP",
+				Attrs:      []string{"0"},
+				Info:       "audio",
+				ConnInfo:   IP4Conn("1.2.3.5"),
+				Bandwidth:  []Bandwidth{{Type: "AS", Value: 64}},
+				Attributes: []Attribute{{Name: "ptime", Value: "20"}},
+			},
+		},
+	}
+	var (
+		scan  = NewScanner(strings.NewReader(f.Dump()))
+		order []byte
+	)
+	for scan.Scan() {
+		order = append(order, scan.Type())
+	}
+	if err := scan.Err(); err != nil {
+		t.Fatal(err)
+	}
+	// RFC 4566, section 5: t= lines come before the attributes of the session
+	if want := "vosiuepcbtamicba"; string(order) != want {
+		t.Errorf("want lines in order %s, got %s", want, order)
+	}
+}
+
+func TestCandidateIPv6(t *testing.T) {
+	f := MustParse(offerHead + "m=audio 5000 RTP/AVP 0\r\n" +
+		"a=candidate:1 1 UDP 2130706431 ::1 5000 typ host\r\n" +
+		"a=candidate:2 1 UDP 1694498815 2001:db8::1 6000 typ srflx raddr fe80::1 rport 5000\r\n")
+	cs, err := f.Medias[0].Candidates()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(cs) != 2 {
+		t.Fatalf("want 2 candidates, got %d", len(cs))
+	}
+	if c := cs[0]; c.Addr != "::1" || c.Port != 5000 || c.Type != CandidateHost {
+		t.Errorf("host candidate: got %+v", c)
+	}
+	if c := cs[1]; c.Addr != "2001:db8::1" || c.Port != 6000 || c.RelAddr != "fe80::1" || c.RelPort != 5000 {
+		t.Errorf("srflx candidate: got %+v", c)
+	}
+	if a := f.Medias[0].Attributes[0]; a.Name != "candidate" || cs[0].String() != a.Value {
+		t.Errorf("candidate not kept: %s:%s", a.Name, a.Value)
+	}
+}
+
+func TestParseDuplicateName(t *testing.T) {
+	const str = "v=0\r\n" +
+		"o=- 1 1 IN IP4 1.2.3.4\r\n" +
+		"s=first\r\n" +
+		"s=second\r\n" +
+		"t=0 0\r\n" +
+		"m=audio 5000 RTP/AVP 0\r\n"
+	if _, err := ParseString(str); !errors.Is(err, ErrInvalid) {
+		t.Errorf("strict: expected %s, got %v", ErrInvalid, err)
+	}
+	f, err := ParseString(str, WithLenient())
+	if err != nil {
+		t.Fatalf("lenient: unexpected error: %s", err)
+	}
+	if f.Session.Name != "first" {
+		t.Errorf("lenient: want name first, got %s", f.Session.Name)
+	}
+	if len(f.Medias) != 1 || len(f.Intervals) != 1 {
+		t.Errorf("lenient: lines after the duplicate not parsed")
+	}
+}
+
+func TestNew(t *testing.T) {
+	if a, b := NextSessionID(), NextSessionID(); a == b {
+		t.Errorf("same id returned twice: %d", a)
+	}
+	var n int64
+	next := func() int64 {
+		n++
+		return 42 * n
+	}
+	f := New("test", IP4Conn("10.0.0.1"), WithSessionID(next))
+	if f.Session.ID != 42 || n != 1 {
+		t.Errorf("generator not used: id %d (%d calls)", f.Session.ID, n)
+	}
+	if g := New("test", IP4Conn("10.0.0.1"), WithSessionID(next)); g.Session.ID != 84 {
+		t.Errorf("generator not used: id %d", g.Session.ID)
+	}
+	if err := f.AddMedia(MediaInfo{Media: "audio", Port: 5000, Proto: "RTP/AVP", Attrs: []string{"0"}}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := f.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if _, err := ParseString(f.Dump()); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestAddMedia(t *testing.T) {
+	audio := MediaInfo{Media: "audio", Port: 5000, Proto: "RTP/AVP", Attrs: []string{"0"}}
+
+	var f File
+	if err := f.AddMedia(audio); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected %s, got %v", ErrInvalid, err)
+	}
+	if len(f.Medias) != 0 {
+		t.Errorf("media added on error")
+	}
+	video := audio
+	video.Media = "video"
+	video.ConnInfo = IP4Conn("10.0.0.1")
+	if err := f.AddMedia(video); err != nil {
+		t.Errorf("media with connection: unexpected error: %s", err)
+	}
+	f.ConnInfo = IP4Conn("10.0.0.2")
+	if err := f.AddMedia(audio); err != nil {
+		t.Errorf("session with connection: unexpected error: %s", err)
+	}
+	if len(f.Medias) != 2 || f.Medias[0].Media != "video" || f.Medias[1].Media != "audio" {
+		t.Errorf("medias not appended in order: %v", f.Medias)
+	}
+}
+
+func TestOriginUser(t *testing.T) {
+	data := []struct {
+		Input string
+		User  string
+		Has   bool
+	}{
+		{Input: "-", User: "", Has: false},
+		{Input: "jdoe", User: "jdoe", Has: true},
+		{Input: "-literal", User: "-literal", Has: true},
+		{Input: "--", User: "--", Has: true},
+	}
+	for _, d := range data {
+		line := "o=" + d.Input + " 1 1 IN IP4 1.2.3.4"
+		f, err := ParseString("v=0\r\n" + line + "\r\ns=-\r\nt=0 0\r\n")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		if f.Session.User != d.User || f.Session.HasUser() != d.Has {
+			t.Errorf("%s: want user %q (%t), got %q (%t)", d.Input, d.User, d.Has, f.Session.User, f.Session.HasUser())
+		}
+		if !strings.Contains(f.Dump(), "\r\n"+line+"\r\n") {
+			t.Errorf("%s: origin not kept: %q", d.Input, f.Dump())
+		}
+	}
+	for _, user := range []string{"", "-"} {
+		s := Session{User: user, ID: 1, Ver: 1, ConnInfo: IP4Conn("1.2.3.4")}
+		if s.HasUser() {
+			t.Errorf("%q: anonymous session has a user", user)
+		}
+		if str := s.String(); str != "- 1 1 IN IP4 1.2.3.4" {
+			t.Errorf("%q: got %s", user, str)
+		}
+	}
+}
+
+func TestMediaProto(t *testing.T) {
+	data := []struct {
+		Proto string
+		Parts int
+		TLS   bool
+		DTLS  bool
+		RTP   bool
+		SCTP  bool
+	}{
+		{Proto: "RTP/AVP", Parts: 2, RTP: true},
+		{Proto: "RTP/SAVPF", Parts: 2, RTP: true},
+		{Proto: "UDP/TLS/RTP/SAVPF", Parts: 4, DTLS: true, RTP: true},
+		{Proto: "TCP/TLS/RTP/AVP", Parts: 4, TLS: true, RTP: true},
+		{Proto: "DTLS/SCTP", Parts: 2, DTLS: true, SCTP: true},
+		{Proto: "UDP/DTLS/SCTP", Parts: 3, DTLS: true, SCTP: true},
+		{Proto: "udp", Parts: 1},
+		{Proto: ""},
+	}
+	for _, d := range data {
+		m := MediaInfo{Proto: d.Proto}
+		if got := m.ProtoParts(); len(got) != d.Parts || (d.Parts > 0 && strings.Join(got, "/") != d.Proto) {
+			t.Errorf("%s: parts: got %q", d.Proto, got)
+		}
+		if m.UsesTLS() != d.TLS {
+			t.Errorf("%s: UsesTLS: want %t", d.Proto, d.TLS)
+		}
+		if m.UsesDTLS() != d.DTLS {
+			t.Errorf("%s: UsesDTLS: want %t", d.Proto, d.DTLS)
+		}
+		if m.UsesRTP() != d.RTP {
+			t.Errorf("%s: UsesRTP: want %t", d.Proto, d.RTP)
+		}
+		if m.UsesSCTP() != d.SCTP {
+			t.Errorf("%s: UsesSCTP: want %t", d.Proto, d.SCTP)
+		}
+	}
+}
+
+func TestParsePartial(t *testing.T) {
+	const str = "v=0\r\n" +
+		"o=- 1 1 IN IP4 1.2.3.4\r\n" +
+		"s=partial\r\n" +
+		"c=IN IP4 1.2.3.4\r\n" +
+		"t=0 0\r\n" +
+		"a=sendrecv\r\n" +
+		"m=audio 5000 RTP/AVP 0\r\n" +
+		"a=ptime:20\r\n" +
+		"m=video 5002 RTP/AVP 96\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n" +
+		"m=audio 5004 RTP/AVP 8\r\n" +
+		"c=IN IP4\r\n" +
+		"m=audio 5006 RTP/AVP 0\r\n"
+	f, err := ParseString(str)
+	if !errors.Is(err, ErrSyntax) {
+		t.Fatalf("expected %s, got %v", ErrSyntax, err)
+	}
+	if f.Session.Name != "partial" || len(f.Intervals) != 1 || !f.HasFlag("sendrecv") {
+		t.Errorf("session not kept: %+v", f.Session)
+	}
+	if len(f.Medias) != 2 {
+		t.Fatalf("want 2 medias, got %d", len(f.Medias))
+	}
+	if m := f.Medias[0]; m.Port != 5000 || len(m.Attributes) != 1 {
+		t.Errorf("first media not kept: %+v", m)
+	}
+	if m := f.Medias[1]; m.Port != 5002 || len(m.Attributes) != 1 {
+		t.Errorf("second media not kept: %+v", m)
+	}
+}
+
+func TestConnInfoConstructors(t *testing.T) {
+	mc4, err := Multicast4("224.2.17.12", 127)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	mc6, err := Multicast6("ff15::101")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	data := []struct {
+		Conn ConnInfo
+		Line string
+	}{
+		{Conn: IP4Conn("10.0.0.1"), Line: "c=IN IP4 10.0.0.1"},
+		{Conn: IP6Conn("2001:db8::1"), Line: "c=IN IP6 2001:db8::1"},
+		{Conn: mc4, Line: "c=IN IP4 224.2.17.12/127"},
+		{Conn: mc6, Line: "c=IN IP6 ff15::101"},
+	}
+	for _, d := range data {
+		f := New("conn", IP4Conn("10.0.0.1"), WithSessionID(func() int64 { return 1 }))
+		f.ConnInfo = d.Conn
+		str := f.Dump()
+		if !strings.Contains(str, "\r\n"+d.Line+"\r\n") {
+			t.Errorf("%s: not found in %q", d.Line, str)
+			continue
+		}
+		g, err := ParseString(str)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Line, err)
+			continue
+		}
+		if g.ConnInfo != d.Conn {
+			t.Errorf("%s: want %+v, got %+v", d.Line, d.Conn, g.ConnInfo)
+		}
+	}
+
+	bad := []func() error{
+		func() error { _, err := Multicast4("10.0.0.1", 1); return err },
+		func() error { _, err := Multicast4("ff15::101", 1); return err },
+		func() error { _, err := Multicast4("224.2.17.12", 256); return err },
+		func() error { _, err := Multicast6("224.2.17.12"); return err },
+		func() error { _, err := Multicast6("2001:db8::1"); return err },
+	}
+	for i, fn := range bad {
+		if err := fn(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%d: expected %s, got %v", i, ErrInvalid, err)
+		}
+	}
+}
+
+func TestIntervalNTP(t *testing.T) {
+	data := []struct {
+		NTP  uint64
+		Unix int64
+	}{
+		{NTP: 2208988800, Unix: 0},
+		{NTP: 3034540800, Unix: 825552000},
+		{NTP: 3155673600, Unix: 946684800},
+		{NTP: 4294967295, Unix: 2085978495},
+		// 2036 rollover: the first second of era 1 is written as 2^32 and
+		// the next ones restart from 1
+		{NTP: 4294967296, Unix: 2085978496},
+		{NTP: 1, Unix: 2085978497},
+		{NTP: 123010304, Unix: 2208988800},
+	}
+	for _, d := range data {
+		if got := NTPToTime(d.NTP).Unix(); got != d.Unix {
+			t.Errorf("%d: NTPToTime: want %d, got %d", d.NTP, d.Unix, got)
+		}
+		if got := TimeToNTP(time.Unix(d.Unix, 0)); got != d.NTP {
+			t.Errorf("%d: TimeToNTP: want %d, got %d", d.Unix, d.NTP, got)
+		}
+		line := fmt.Sprintf("t=%d 0", d.NTP)
+		f, err := ParseString("v=0\r\no=- 1 1 IN IP4 1.2.3.4\r\ns=-\r\n" + line + "\r\n")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", line, err)
+			continue
+		}
+		if i := f.Intervals[0]; i.Starts.Unix() != d.Unix || !i.Ends.IsZero() {
+			t.Errorf("%s: want start %d, got %s - %s", line, d.Unix, i.Starts, i.Ends)
+		}
+		if !strings.Contains(f.Dump(), "\r\n"+line+"\r\n") {
+			t.Errorf("%s: not written back: %q", line, f.Dump())
+		}
+	}
+}
+
+func TestParseReaderGzip(t *testing.T) {
+	var buf bytes.Buffer
+	z := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(z, sample); err != nil {
+		t.Fatal(err)
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := MustParse(sample).Dump()
+
+	f, err := ParseReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("gzip: unexpected error: %s", err)
+	}
+	if got := f.Dump(); got != want {
+		t.Errorf("gzip: want %q, got %q", want, got)
+	}
+	f, err = ParseReader(strings.NewReader(sample))
+	if err != nil {
+		t.Fatalf("plain: unexpected error: %s", err)
+	}
+	if got := f.Dump(); got != want {
+		t.Errorf("plain: want %q, got %q", want, got)
+	}
+	if _, err := Parse(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Errorf("Parse accepted gzip input")
+	}
+	if _, err := ParseReader(bytes.NewReader(buf.Bytes()[:10])); err == nil {
+		t.Errorf("truncated gzip input accepted")
+	}
+}
+
+func TestParseBandwidthType(t *testing.T) {
+	data := []struct {
+		Line         string
+		Options      []Option
+		Type         string
+		Experimental bool
+		Err          error
+	}{
+		{Line: "b=X-YZ:128", Type: "X-YZ", Experimental: true},
+		{Line: "b=AS:128", Type: "AS"},
+		{Line: "b=TIAS:128", Type: "TIAS"},
+		{Line: "b=FOO:128", Err: ErrInvalid},
+		{Line: "b=FOO:128", Options: []Option{WithLenient()}, Type: "FOO"},
+		{Line: "b=X-YZ:128", Options: []Option{WithLenient()}, Type: "X-YZ", Experimental: true},
+	}
+	for _, d := range data {
+		f, err := ParseString(offerHead+"m=audio 5000 RTP/AVP 0\r\n"+d.Line+"\r\n", d.Options...)
+		if d.Err != nil {
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%s: expected %s, got %v", d.Line, d.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Line, err)
+			continue
+		}
+		bs := f.Medias[0].Bandwidth
+		if len(bs) != 1 || bs[0].Type != d.Type || bs[0].Value != 128 {
+			t.Errorf("%s: got %v", d.Line, bs)
+			continue
+		}
+		if bs[0].IsExperimental() != d.Experimental {
+			t.Errorf("%s: want experimental %t", d.Line, d.Experimental)
+		}
+	}
+}
+
+func TestConnInfoIP(t *testing.T) {
+	data := []struct {
+		Conn      ConnInfo
+		IP        string
+		Multicast bool
+		Err       error
+	}{
+		{Conn: IP4Conn("10.0.0.1"), IP: "10.0.0.1"},
+		{Conn: IP6Conn("2001:db8::1"), IP: "2001:db8::1"},
+		{Conn: ConnInfo{NetType: NetTypeIN, AddrType: AddrType4, Addr: "224.2.17.12", TTL: 127}, IP: "224.2.17.12", Multicast: true},
+		{Conn: IP6Conn("ff15::101"), IP: "ff15::101", Multicast: true},
+		{Conn: IP4Conn("host.example.com"), Err: ErrFQDN},
+		{Conn: IP6Conn("host.example.com"), Err: ErrFQDN},
+		{Conn: IP4Conn("2001:db8::1"), Err: ErrInvalid},
+		{Conn: IP6Conn("10.0.0.1"), Err: ErrInvalid},
+	}
+	for _, d := range data {
+		ip, err := d.Conn.IP()
+		if d.Err != nil {
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%s: expected %s, got %v", d.Conn, d.Err, err)
+			}
+			if d.Conn.IsMulticast() {
+				t.Errorf("%s: multicast", d.Conn)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Conn, err)
+			continue
+		}
+		if ip.String() != d.IP {
+			t.Errorf("%s: want %s, got %s", d.Conn, d.IP, ip)
+		}
+		if d.Conn.IsMulticast() != d.Multicast {
+			t.Errorf("%s: want multicast %t", d.Conn, d.Multicast)
+		}
+	}
+}
+
+func TestParseString(t *testing.T) {
+	f, err := ParseString(sample)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	g, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !f.Equal(g) {
+		t.Errorf("ParseString and Parse mismatched")
+	}
+	if _, err := ParseString("v=0\r\no=jdoe\r\n"); !errors.Is(err, ErrSyntax) {
+		t.Errorf("expected %s, got %v", ErrSyntax, err)
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	if f := MustParse(sample); len(f.Medias) != 2 {
+		t.Errorf("want 2 medias, got %d", len(f.Medias))
+	}
+	defer func() {
+		err := recover()
+		if err == nil {
+			t.Fatalf("MustParse did not panic")
+		}
+		if str, ok := err.(string); !ok || !strings.HasPrefix(str, "sdp: MustParse: ") {
+			t.Errorf("unexpected panic value: %v", err)
+		}
+	}()
+	MustParse("v=0\r\no=jdoe\r\n")
+}
+
+func TestEachMedia(t *testing.T) {
+	f := MustParse("v=0\r\n" +
+		"o=alice 1 1 IN IP4 10.0.0.1\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 10.0.0.1\r\n" +
+		"b=AS:256\r\n" +
+		"t=0 0\r\n" +
+		"a=recvonly\r\n" +
+		"m=audio 5000 RTP/AVP 0\r\n" +
+		"m=video 5002 RTP/AVP 96\r\n" +
+		"c=IN IP4 10.0.0.2\r\n" +
+		"b=AS:1024\r\n" +
+		"a=sendrecv\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n" +
+		"m=application 5004 UDP/BFCP *\r\n")
+	want := []MediaContext{
+		{
+			ConnInfo:  IP4Conn("10.0.0.1"),
+			Bandwidth: []Bandwidth{{Type: "AS", Value: 256}},
+			Direction: RecvOnly,
+		},
+		{
+			ConnInfo:  IP4Conn("10.0.0.2"),
+			Bandwidth: []Bandwidth{{Type: "AS", Value: 1024}},
+			Direction: SendRecv,
+		},
+		{
+			ConnInfo:  IP4Conn("10.0.0.1"),
+			Bandwidth: []Bandwidth{{Type: "AS", Value: 256}},
+			Direction: RecvOnly,
+		},
+	}
+	var seen int
+	err := f.EachMedia(func(i int, m MediaInfo, ctx MediaContext) error {
+		seen++
+		if m.Media != f.Medias[i].Media {
+			t.Errorf("media #%d: want %s, got %s", i, f.Medias[i].Media, m.Media)
+		}
+		w := want[i]
+		if ctx.ConnInfo != w.ConnInfo {
+			t.Errorf("media #%d: want conn %s, got %s", i, w.ConnInfo, ctx.ConnInfo)
+		}
+		if len(ctx.Bandwidth) != 1 || ctx.Bandwidth[0] != w.Bandwidth[0] {
+			t.Errorf("media #%d: want bandwidth %v, got %v", i, w.Bandwidth, ctx.Bandwidth)
+		}
+		if ctx.Direction != w.Direction {
+			t.Errorf("media #%d: want direction %s, got %s", i, w.Direction, ctx.Direction)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if seen != len(f.Medias) {
+		t.Errorf("want %d medias visited, got %d", len(f.Medias), seen)
+	}
+
+	stop := errors.New("stop")
+	seen = 0
+	err = f.EachMedia(func(i int, _ MediaInfo, _ MediaContext) error {
+		seen++
+		if i == 1 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("expected %s, got %v", stop, err)
+	}
+	if seen != 2 {
+		t.Errorf("iteration not stopped: %d medias visited", seen)
+	}
+}
+
+func TestTotalBandwidth(t *testing.T) {
+	const head = "v=0\r\n" +
+		"o=- 1 1 IN IP4 10.0.0.1\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 10.0.0.1\r\n"
+	const medias = "m=audio 5000 RTP/AVP 0\r\n" +
+		"b=AS:64\r\n" +
+		"m=video 5002 RTP/AVP 96\r\n" +
+		"b=AS:512\r\n" +
+		"b=TIAS:500000\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n" +
+		"m=video 5004 RTP/AVP 96\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n" +
+		"m=video 0 RTP/AVP 96\r\n" +
+		"b=AS:2048\r\n"
+	data := []struct {
+		Session string
+		Type    string
+		Total   int64
+	}{
+		{Type: "AS", Total: 576000},
+		{Session: "b=AS:128\r\n", Type: "AS", Total: 704000},
+		{Type: "TIAS", Total: 500000},
+		{Session: "b=TIAS:100000\r\n", Type: "TIAS", Total: 700000},
+		{Session: "b=AS:128\r\n", Type: "CT"},
+	}
+	for _, d := range data {
+		f := MustParse(head + d.Session + "t=0 0\r\n" + medias)
+		if got := f.TotalBandwidth(d.Type); got != d.Total {
+			t.Errorf("%s (%q): want %d, got %d", d.Type, d.Session, d.Total, got)
+		}
+	}
+}
+
+func TestMakePermanent(t *testing.T) {
+	f := MustParse("v=0\r\n" +
+		"o=- 1 1 IN IP4 10.0.0.1\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 10.0.0.1\r\n" +
+		"t=2873397496 2873404696\r\n" +
+		"t=2873404696 2873410000\r\n" +
+		"m=audio 5000 RTP/AVP 0\r\n")
+	if len(f.Intervals) != 2 || f.IsPermanent() {
+		t.Fatalf("unexpected intervals: %v", f.Intervals)
+	}
+	f.MakePermanent()
+	if !f.IsPermanent() {
+		t.Errorf("session not permanent: %v", f.Intervals)
+	}
+	str := f.Dump()
+	if strings.Count(str, "t=") != 1 || !strings.Contains(str, "\r\nt=0 0\r\nm=audio") {
+		t.Errorf("want a single t=0 0 line, got %q", str)
+	}
+	g, err := ParseString(str)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !g.IsPermanent() {
+		t.Errorf("permanent interval not parsed back: %v", g.Intervals)
+	}
+}
+
+func TestParseMissingTiming(t *testing.T) {
+	const str = "v=0\r\n" +
+		"o=- 1 1 IN IP4 1.2.3.4\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 1.2.3.4\r\n" +
+		"m=audio 5000 RTP/AVP 0\r\n"
+
+	f, err := ParseString(str)
+	if err != nil {
+		t.Fatalf("strict: unexpected error: %s", err)
+	}
+	if len(f.Intervals) != 0 || f.SyntheticTiming {
+		t.Errorf("strict: interval added: %v", f.Intervals)
+	}
+	err = f.Validate()
+	if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), RuleTiming) {
+		t.Errorf("strict: expected %s violation, got %v", RuleTiming, err)
+	}
+
+	f, err = ParseString(str, WithLenient())
+	if err != nil {
+		t.Fatalf("lenient: unexpected error: %s", err)
+	}
+	if !f.SyntheticTiming || !f.IsPermanent() {
+		t.Errorf("lenient: want a synthetic permanent interval, got %v", f.Intervals)
+	}
+	if len(f.Medias) != 1 {
+		t.Errorf("lenient: want 1 media, got %d", len(f.Medias))
+	}
+	if err := f.Validate(); err != nil {
+		t.Errorf("lenient: unexpected error: %s", err)
+	}
+	if !strings.Contains(f.Dump(), "\r\nt=0 0\r\n") {
+		t.Errorf("lenient: t= line not written: %q", f.Dump())
+	}
+
+	f, _ = ParseString(sample, WithLenient())
+	if f.SyntheticTiming {
+		t.Errorf("timing marked as synthetic with a t= line")
+	}
+}
+
+func TestSetSourceFilter(t *testing.T) {
+	f := MustParse(offerHead +
+		"m=video 50000 RTP/AVP 96\r\n" +
+		"c=IN IP4 232.3.4.5/127\r\n" +
+		"a=source-filter: excl IN IP4 232.3.4.5 10.0.0.9\r\n" +
+		"a=rtpmap:96 raw/90000\r\n")
+	s := SourceInfo{
+		Mode:     ModeIncl,
+		NetType:  NetTypeIN,
+		AddrType: AddrType4,
+		Addr:     "232.3.4.5",
+		List:     []string{"192.0.2.10", "192.0.2.11", "192.0.2.12"},
+	}
+	if err := f.Medias[0].SetSourceFilter(s); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	const line = "a=source-filter: incl IN IP4 232.3.4.5 192.0.2.10 192.0.2.11 192.0.2.12\r\n"
+	str := f.Dump()
+	if strings.Count(str, "source-filter") != 1 || !strings.Contains(str, "\r\n"+line) {
+		t.Fatalf("filter not replaced: %q", str)
+	}
+	g, err := ParseString(str)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err := g.Medias[0].SourceFilter()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !got.Include() || got.String() != s.String() || len(got.List) != 3 {
+		t.Errorf("want %s, got %s", s, got)
+	}
+	if g.Dump() != str {
+		t.Errorf("dumps mismatched:\nwant: %q\ngot:  %q", str, g.Dump())
+	}
+
+	data := []SourceInfo{
+		{Mode: "only", NetType: NetTypeIN, AddrType: AddrType4, Addr: "232.3.4.5", List: s.List},
+		{Mode: ModeIncl, NetType: "ATM", AddrType: AddrType4, Addr: "232.3.4.5", List: s.List},
+		{Mode: ModeIncl, NetType: NetTypeIN, AddrType: "IP5", Addr: "232.3.4.5", List: s.List},
+		{Mode: ModeIncl, NetType: NetTypeIN, AddrType: AddrType4, Addr: "232.3.4.5"},
+	}
+	for _, d := range data {
+		m := g.Medias[0]
+		if err := m.SetSourceFilter(d); err == nil {
+			t.Errorf("%s: expected error", d)
+		}
+		if got, _ := m.SourceFilter(); got.String() != s.String() {
+			t.Errorf("%s: filter modified: %s", d, got)
+		}
+	}
+	s.AddrType, s.Addr = "*", "*"
+	if err := g.Medias[0].SetSourceFilter(s); err != nil {
+		t.Errorf("%s: unexpected error: %s", s, err)
+	}
+	if _, err := ParseString(offerHead + "m=audio 5000 RTP/AVP 0\r\nc=IN * 232.3.4.5\r\n"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("wildcard address type in c=: expected %s, got %v", ErrInvalid, err)
+	}
+}
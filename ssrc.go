@@ -0,0 +1,212 @@
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Semantics of the ssrc-group attribute (RFC 5576). FID is also a semantics
+// of the group attribute (RFC 5888).
+const (
+	SemanticsFID   = "FID"
+	SemanticsFEC   = "FEC"
+	SemanticsFECFR = "FEC-FR"
+	SemanticsSIM   = "SIM"
+)
+
+type SSRCGroup struct {
+	Semantics string
+	SSRCs     []uint32
+}
+
+func (g SSRCGroup) String() string {
+	var str strings.Builder
+	str.WriteString(g.Semantics)
+	for _, id := range g.SSRCs {
+		str.WriteByte(' ')
+		str.WriteString(strconv.FormatUint(uint64(id), 10))
+	}
+	return str.String()
+}
+
+func (g SSRCGroup) IsFEC() bool {
+	return g.Semantics == SemanticsFEC || g.Semantics == SemanticsFECFR
+}
+
+func (m MediaInfo) SSRCGroups() ([]SSRCGroup, error) {
+	var arr []SSRCGroup
+	for _, a := range m.Attributes {
+		if a.Name != "ssrc-group" {
+			continue
+		}
+		g, err := parseSSRCGroup(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, g)
+	}
+	return arr, nil
+}
+
+// FECGroups returns the ssrc groups using the FEC or FEC-FR semantics.
+func (m MediaInfo) FECGroups() ([]SSRCGroup, error) {
+	gs, err := m.SSRCGroups()
+	if err != nil {
+		return nil, err
+	}
+	var arr []SSRCGroup
+	for _, g := range gs {
+		if g.IsFEC() {
+			arr = append(arr, g)
+		}
+	}
+	return arr, nil
+}
+
+// ssrc-group:<semantics> <ssrc-id> ...
+func parseSSRCGroup(str string) (SSRCGroup, error) {
+	var (
+		g     SSRCGroup
+		parts = strings.Fields(str)
+	)
+	if len(parts) < 2 {
+		return g, fmt.Errorf("%w: ssrc-group (%s)", ErrSyntax, str)
+	}
+	g.Semantics = parts[0]
+	for _, p := range parts[1:] {
+		id, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return g, fmt.Errorf("%w - ssrc-group: %s", ErrSyntax, err)
+		}
+		g.SSRCs = append(g.SSRCs, uint32(id))
+	}
+	switch g.Semantics {
+	case SemanticsFID, SemanticsFEC, SemanticsFECFR, SemanticsSIM:
+		if len(g.SSRCs) < 2 {
+			return g, fmt.Errorf("%w: ssrc-group %s needs at least two ssrc", ErrInvalid, g.Semantics)
+		}
+	}
+	return g, nil
+}
+
+// SSRC gathers the source attributes (a=ssrc, RFC 5576) defined for the same
+// synchronization source.
+type SSRC struct {
+	ID         uint32
+	Attributes []Attribute
+}
+
+func (s SSRC) Get(name string) (string, bool) {
+	a, ok := findAttributes(name, s.Attributes)
+	return a.Value, ok
+}
+
+func (s SSRC) CNAME() string {
+	str, _ := s.Get("cname")
+	return str
+}
+
+// SSRCs returns the sources of m in the order of their first a=ssrc line.
+func (m MediaInfo) SSRCs() ([]SSRC, error) {
+	var (
+		arr   []SSRC
+		index = make(map[uint32]int)
+	)
+	for _, a := range m.Attributes {
+		if a.Name != "ssrc" {
+			continue
+		}
+		id, attr, err := parseSSRC(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		x, ok := index[id]
+		if !ok {
+			x = len(arr)
+			index[id] = x
+			arr = append(arr, SSRC{ID: id})
+		}
+		arr[x].Attributes = append(arr[x].Attributes, attr)
+	}
+	return arr, nil
+}
+
+// ssrc:<ssrc-id> <attribute>[:<value>]
+func parseSSRC(str string) (uint32, Attribute, error) {
+	var attr Attribute
+	x := strings.Index(str, " ")
+	if x <= 0 || x == len(str)-1 {
+		return 0, attr, fmt.Errorf("%w: ssrc (%s)", ErrSyntax, str)
+	}
+	id, err := strconv.ParseUint(str[:x], 10, 32)
+	if err != nil {
+		return 0, attr, fmt.Errorf("%w - ssrc: %s", ErrSyntax, err)
+	}
+	attr.Name = str[x+1:]
+	if x := strings.Index(attr.Name, ":"); x >= 0 {
+		attr.Name, attr.Value = attr.Name[:x], attr.Name[x+1:]
+	}
+	return uint32(id), attr, nil
+}
+
+// Stream is a media stream made of all the sources sharing the same cname.
+type Stream struct {
+	CNAME string
+	SSRCs []uint32
+	MSID  string
+}
+
+// Streams correlates the sources of m by their cname. A source without cname
+// that is part of a FID or FEC group (retransmission, repair) is associated
+// with the stream of the first source of the group. The msid of a stream is
+// the one of the media or, without it, the msid given by its sources.
+func (m MediaInfo) Streams() ([]Stream, error) {
+	ssrcs, err := m.SSRCs()
+	if err != nil {
+		return nil, err
+	}
+	groups, err := m.SSRCGroups()
+	if err != nil {
+		return nil, err
+	}
+	cnames := make(map[uint32]string)
+	for _, s := range ssrcs {
+		if c := s.CNAME(); c != "" {
+			cnames[s.ID] = c
+		}
+	}
+	for _, g := range groups {
+		if g.Semantics != SemanticsFID && !g.IsFEC() {
+			continue
+		}
+		c, ok := cnames[g.SSRCs[0]]
+		if !ok {
+			continue
+		}
+		for _, id := range g.SSRCs[1:] {
+			if _, ok := cnames[id]; !ok {
+				cnames[id] = c
+			}
+		}
+	}
+	msid, _ := findAttributes("msid", m.Attributes)
+	var (
+		arr   []Stream
+		index = make(map[string]int)
+	)
+	for _, s := range ssrcs {
+		c := cnames[s.ID]
+		x, ok := index[c]
+		if !ok {
+			x = len(arr)
+			index[c] = x
+			arr = append(arr, Stream{CNAME: c, MSID: msid.Value})
+		}
+		arr[x].SSRCs = append(arr[x].SSRCs, s.ID)
+		if arr[x].MSID == "" {
+			arr[x].MSID, _ = s.Get("msid")
+		}
+	}
+	return arr, nil
+}
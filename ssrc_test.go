@@ -0,0 +1,77 @@
+package sdp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFECGroups(t *testing.T) {
+	m := MustParse(offerHead +
+		"m=video 5000 RTP/AVP 96 97 98\r\n" +
+		"a=ssrc-group:FID 1 2\r\n" +
+		"a=ssrc-group:FEC-FR 1 3\r\n" +
+		"a=ssrc-group:SIM 1 4 5\r\n" +
+		"a=ssrc-group:FEC 4 6\r\n").Medias[0]
+	gs, err := m.SSRCGroups()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(gs) != 4 {
+		t.Fatalf("want 4 groups, got %d", len(gs))
+	}
+	fs, err := m.FECGroups()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []string{"FEC-FR 1 3", "FEC 4 6"}
+	if len(fs) != len(want) {
+		t.Fatalf("want %d FEC groups, got %v", len(want), fs)
+	}
+	for i, g := range fs {
+		if g.String() != want[i] {
+			t.Errorf("%d: want %s, got %s", i, want[i], g)
+		}
+	}
+
+	data := []struct {
+		Value string
+		Err   error
+	}{
+		{Value: "FID 1", Err: ErrInvalid},
+		{Value: "FEC-FR 1", Err: ErrInvalid},
+		{Value: "FEC 1 x", Err: ErrSyntax},
+		{Value: "FEC", Err: ErrSyntax},
+	}
+	for _, d := range data {
+		m := MustParse(offerHead + "m=video 5000 RTP/AVP 96\r\na=ssrc-group:" + d.Value + "\r\n").Medias[0]
+		if _, err := m.FECGroups(); !errors.Is(err, d.Err) {
+			t.Errorf("%s: expected %s, got %v", d.Value, d.Err, err)
+		}
+	}
+}
+
+func TestStreams(t *testing.T) {
+	m := MustParse(offerHead +
+		"m=video 5000 RTP/AVP 96 97\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n" +
+		"a=rtpmap:97 rtx/90000\r\n" +
+		"a=fmtp:97 apt=96\r\n" +
+		"a=ssrc-group:FID 1111 2222\r\n" +
+		"a=ssrc:1111 cname:user@example\r\n" +
+		"a=ssrc:1111 msid:stream track\r\n" +
+		"a=ssrc:2222 msid:stream track\r\n" +
+		"a=ssrc:3333 cname:other\r\n").Medias[0]
+	ss, err := m.Streams()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(ss) != 2 {
+		t.Fatalf("want 2 streams, got %v", ss)
+	}
+	if s := ss[0]; s.CNAME != "user@example" || s.MSID != "stream track" || len(s.SSRCs) != 2 || s.SSRCs[0] != 1111 || s.SSRCs[1] != 2222 {
+		t.Errorf("rtx not associated with its primary stream: %+v", s)
+	}
+	if s := ss[1]; s.CNAME != "other" || s.MSID != "" || len(s.SSRCs) != 1 || s.SSRCs[0] != 3333 {
+		t.Errorf("unexpected stream: %+v", s)
+	}
+}
@@ -0,0 +1,82 @@
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// StreamParams describes a RTP stream with a single codec. It is used by
+// FromStream to build the corresponding media description.
+type StreamParams struct {
+	Media string
+	// Proto defaults to RTP/AVP.
+	Proto string
+	// Addr is left empty to use the connection information of the session.
+	Addr ConnInfo
+	Port uint16
+
+	Payload   uint8
+	Codec     string
+	ClockRate int
+	Channels  int
+	// Params are written in the fmtp attribute of the payload, if any.
+	Params []Attribute
+
+	// Direction is only written when set.
+	Direction Direction
+}
+
+// FromStream builds the media description of the stream described by params:
+// the m= line with the payload as only format, the rtpmap attribute, the fmtp
+// attribute when params has format parameters and the direction attribute.
+// An error wrapping ErrInvalid is returned when params is incomplete or when
+// one of its values is invalid.
+func FromStream(params StreamParams) (MediaInfo, error) {
+	var m MediaInfo
+	if params.Media == "" {
+		return m, fmt.Errorf("%w: stream without media type", ErrInvalid)
+	}
+	if params.Codec == "" {
+		return m, fmt.Errorf("%w: stream without codec", ErrInvalid)
+	}
+	if params.Channels < 0 {
+		return m, fmt.Errorf("%w: stream channels (%d)", ErrInvalid, params.Channels)
+	}
+	if params.Direction != "" && !isDirection(string(params.Direction)) {
+		return m, fmt.Errorf("%w: stream direction (%s)", ErrInvalid, params.Direction)
+	}
+	m = MediaInfo{
+		Media:    params.Media,
+		Port:     params.Port,
+		Proto:    params.Proto,
+		ConnInfo: params.Addr,
+	}
+	if m.Proto == "" {
+		m.Proto = "RTP/AVP"
+	}
+	if !m.UsesRTP() {
+		return MediaInfo{}, fmt.Errorf("%w: stream protocol %s does not use RTP", ErrInvalid, m.Proto)
+	}
+	r := RTPMap{
+		Payload:   params.Payload,
+		Encoding:  params.Codec,
+		ClockRate: params.ClockRate,
+		Channels:  params.Channels,
+	}
+	if _, err := parseRTPMap(r.String()); err != nil {
+		return MediaInfo{}, err
+	}
+	m.Attrs = append(m.Attrs, strconv.Itoa(int(r.Payload)))
+	m.Attributes = append(m.Attributes, r.Attribute())
+	if len(params.Params) > 0 {
+		p := FormatParams{
+			Payload: r.Payload,
+			Params:  params.Params,
+		}
+		m.Attributes = append(m.Attributes, p.Attribute())
+	}
+	if params.Direction != "" {
+		m.SetDirection(params.Direction)
+	}
+	return m, nil
+}
@@ -0,0 +1,86 @@
+package sdp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFromStream(t *testing.T) {
+	params := StreamParams{
+		Media:     "audio",
+		Proto:     "UDP/TLS/RTP/SAVPF",
+		Port:      9,
+		Payload:   111,
+		Codec:     "opus",
+		ClockRate: 48000,
+		Channels:  2,
+		Params: []Attribute{
+			{Name: "minptime", Value: "10"},
+			{Name: "useinbandfec", Value: "1"},
+		},
+		Direction: SendOnly,
+	}
+	m, err := FromStream(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := "m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n" +
+		"a=fmtp:111 minptime=10;useinbandfec=1\r\n" +
+		"a=sendonly\r\n"
+	if got := mediaText(m); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	f := MustParse(offerHead)
+	if err := f.AddMedia(m); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := f.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	g, err := ParseString(f.Dump())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	c, ok, err := g.Medias[0].Codec("opus")
+	if err != nil || !ok {
+		t.Fatalf("opus not found (%v)", err)
+	}
+	if c.Payload != 111 || c.ClockRate != 48000 || c.Channels != 2 {
+		t.Errorf("unexpected codec %s", c)
+	}
+	p, err := g.Medias[0].FormatParams(111)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v, _ := p.Get("useinbandfec"); v != "1" {
+		t.Errorf("fmtp not parsed back: %v", p)
+	}
+	if d, _ := g.Medias[0].Direction(); d != SendOnly {
+		t.Errorf("want %s, got %s", SendOnly, d)
+	}
+
+	m, err = FromStream(StreamParams{Media: "audio", Port: 5000, Codec: "PCMU", ClockRate: 8000})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := mediaText(m); got != "m=audio 5000 RTP/AVP 0\r\na=rtpmap:0 PCMU/8000\r\n" {
+		t.Errorf("unexpected media %q", got)
+	}
+
+	data := []StreamParams{
+		{Codec: "opus", ClockRate: 48000},
+		{Media: "audio", ClockRate: 48000},
+		{Media: "audio", Codec: "opus"},
+		{Media: "audio", Codec: "opus", ClockRate: 48000, Channels: -1},
+		{Media: "audio", Codec: "opus", ClockRate: 48000, Payload: 128},
+		{Media: "audio", Codec: "opus", ClockRate: 48000, Direction: "both"},
+		{Media: "audio", Codec: "opus", ClockRate: 48000, Proto: "UDP/DTLS/SCTP"},
+	}
+	for _, d := range data {
+		if _, err := FromStream(d); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%+v: expected %s, got %v", d, ErrInvalid, err)
+		}
+	}
+}
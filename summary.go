@@ -0,0 +1,132 @@
+package sdp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Summary is a condensed view of a session description meant to be read by
+// humans (logs, debugging tools).
+type Summary struct {
+	Name   string
+	Origin string
+	Medias []MediaSummary
+}
+
+// MediaSummary describes a media of a Summary. Direction is the effective
+// direction of the media: a media without direction attribute gets the one of
+// the session.
+type MediaSummary struct {
+	Media     string
+	Port      uint16
+	Proto     string
+	Direction Direction
+	Codecs    []string
+}
+
+// Rejected reports whether the media has been rejected (port set to zero).
+func (m MediaSummary) Rejected() bool {
+	return m.Port == 0
+}
+
+func (s Summary) String() string {
+	var str strings.Builder
+	fmt.Fprintf(&str, "%s (%s)\n", s.Name, s.Origin)
+	for i, m := range s.Medias {
+		fmt.Fprintf(&str, "  #%d %s %d %s: %s", i, m.Media, m.Port, m.Proto, m.Direction)
+		if m.Rejected() {
+			str.WriteString(" [rejected]")
+		}
+		if len(m.Codecs) > 0 {
+			fmt.Fprintf(&str, " [%s]", strings.Join(m.Codecs, ", "))
+		}
+		str.WriteByte('\n')
+	}
+	return str.String()
+}
+
+// Summary returns the summary of f. The codecs of a media are the ones given by
+// Formats; for medias not using RTP, the formats of the m= line are used.
+func (f File) Summary() Summary {
+	s := Summary{
+		Name:   f.Session.Name,
+		Origin: f.Session.String(),
+	}
+	for _, m := range f.Medias {
+		ms := MediaSummary{
+			Media:     m.Media,
+			Port:      m.Port,
+			Proto:     m.Proto,
+			Direction: f.EffectiveDirection(m),
+		}
+		if m.UsesRTP() {
+			formats, _ := m.Formats()
+			for _, c := range formats {
+				ms.Codecs = append(ms.Codecs, c.String())
+			}
+		} else {
+			ms.Codecs = append(ms.Codecs, m.Attrs...)
+		}
+		s.Medias = append(s.Medias, ms)
+	}
+	return s
+}
+
+// Stats gives the shape of a session description, for metrics and labels.
+type Stats struct {
+	MediaCount int
+	// CodecCount gives the number of medias using each codec. Codecs are
+	// identified by their encoding name in lower case.
+	CodecCount map[string]int
+	HasICE     bool
+	HasDTLS    bool
+	// Directions gives the number of medias using each direction, once
+	// resolved with the direction of the session.
+	Directions map[Direction]int
+}
+
+// Stats returns the statistics of f. Attributes that can not be parsed are
+// ignored.
+func (f File) Stats() Stats {
+	s := Stats{
+		MediaCount: len(f.Medias),
+		CodecCount: make(map[string]int),
+		Directions: make(map[Direction]int),
+	}
+	s.HasICE = hasICE(f.Attributes)
+	if fs, _ := fingerprintsOf(f.Attributes); len(fs) > 0 {
+		s.HasDTLS = true
+	}
+	for _, m := range f.Medias {
+		s.Directions[f.EffectiveDirection(m)]++
+		if hasICE(m.Attributes) {
+			s.HasICE = true
+		}
+		if fs, _ := fingerprintsOf(m.Attributes); len(fs) > 0 || m.UsesDTLS() {
+			s.HasDTLS = true
+		}
+		if !m.UsesRTP() {
+			continue
+		}
+		formats, _ := m.Formats()
+		seen := make(map[string]bool)
+		for _, c := range formats {
+			name := strings.ToLower(c.Encoding)
+			if !seen[name] {
+				seen[name] = true
+				s.CodecCount[name]++
+			}
+		}
+	}
+	return s
+}
+
+// hasICE reports whether attrs has a candidate or an ICE (ice-*) attribute.
+func hasICE(attrs []Attribute) bool {
+	for _, a := range attrs {
+		if a.Name == "candidate" || hasNamePrefix(a, "ice-") {
+			return true
+		}
+	}
+	return false
+}
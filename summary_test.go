@@ -0,0 +1,107 @@
+package sdp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummaryInactive(t *testing.T) {
+	f := MustParse(offerHead +
+		"a=inactive\r\n" +
+		"m=audio 5000 RTP/AVP 0 96\r\n" +
+		"a=rtpmap:96 opus/48000/2\r\n" +
+		"m=video 5002 RTP/AVP 97\r\n" +
+		"a=rtpmap:97 VP8/90000\r\n" +
+		"m=application 5004 UDP/DTLS/SCTP webrtc-datachannel\r\n" +
+		"m=video 0 RTP/AVP 97\r\n")
+	s := f.Summary()
+	if len(s.Medias) != 4 {
+		t.Fatalf("want 4 medias, got %d", len(s.Medias))
+	}
+	for i, m := range s.Medias {
+		if m.Direction != Inactive {
+			t.Errorf("media #%d: want %s, got %s", i, Inactive, m.Direction)
+		}
+	}
+	if cs := s.Medias[0].Codecs; len(cs) != 2 {
+		t.Errorf("codecs of an inactive media not kept: %v", cs)
+	}
+	if !s.Medias[3].Rejected() || s.Medias[0].Rejected() {
+		t.Errorf("rejected medias not reported")
+	}
+	str := s.String()
+	if n := strings.Count(str, ": inactive"); n != 4 {
+		t.Errorf("want 4 inactive medias, got %d: %q", n, str)
+	}
+
+	f.Medias[1].Attributes = append(f.Medias[1].Attributes, Attribute{Name: "sendonly"})
+	if d := f.Summary().Medias[1].Direction; d != SendOnly {
+		t.Errorf("media direction not used: %s", d)
+	}
+}
+
+func TestStats(t *testing.T) {
+	f := MustParse(offerHead +
+		"a=group:BUNDLE 0 1 2\r\n" +
+		"a=ice-options:trickle\r\n" +
+		"m=audio 9 UDP/TLS/RTP/SAVPF 111 0 8 126\r\n" +
+		"a=mid:0\r\n" +
+		"a=ice-ufrag:F7gI\r\n" +
+		"a=ice-pwd:x9cml/YzichV2+XlhiMu8g\r\n" +
+		"a=fingerprint:sha-256 AB:CD:EF\r\n" +
+		"a=setup:actpass\r\n" +
+		"a=sendrecv\r\n" +
+		"a=rtpmap:111 OPUS/48000/2\r\n" +
+		"a=rtpmap:126 telephone-event/8000\r\n" +
+		"m=video 9 UDP/TLS/RTP/SAVPF 96 97 98 99\r\n" +
+		"a=mid:1\r\n" +
+		"a=recvonly\r\n" +
+		"a=rtpmap:96 VP8/90000\r\n" +
+		"a=rtpmap:97 rtx/90000\r\n" +
+		"a=rtpmap:98 H264/90000\r\n" +
+		"a=rtpmap:99 rtx/90000\r\n" +
+		"m=video 9 UDP/TLS/RTP/SAVPF 100\r\n" +
+		"a=mid:2\r\n" +
+		"a=rtpmap:100 vp8/90000\r\n" +
+		"m=application 9 UDP/DTLS/SCTP webrtc-datachannel\r\n" +
+		"a=mid:3\r\n" +
+		"a=sctp-port:5000\r\n")
+	s := f.Stats()
+	if s.MediaCount != 4 {
+		t.Errorf("want 4 medias, got %d", s.MediaCount)
+	}
+	if !s.HasICE || !s.HasDTLS {
+		t.Errorf("want ICE and DTLS, got %t and %t", s.HasICE, s.HasDTLS)
+	}
+	codecs := map[string]int{
+		"opus":            1,
+		"pcmu":            1,
+		"pcma":            1,
+		"telephone-event": 1,
+		"vp8":             2,
+		"rtx":             1,
+		"h264":            1,
+	}
+	if len(s.CodecCount) != len(codecs) {
+		t.Errorf("want %d codecs, got %v", len(codecs), s.CodecCount)
+	}
+	for name, n := range codecs {
+		if s.CodecCount[name] != n {
+			t.Errorf("%s: want %d medias, got %d", name, n, s.CodecCount[name])
+		}
+	}
+	dirs := map[Direction]int{SendRecv: 3, RecvOnly: 1}
+	if len(s.Directions) != len(dirs) {
+		t.Errorf("want %d directions, got %v", len(dirs), s.Directions)
+	}
+	for d, n := range dirs {
+		if s.Directions[d] != n {
+			t.Errorf("%s: want %d medias, got %d", d, n, s.Directions[d])
+		}
+	}
+
+	s = MustParse(offerHead + "m=audio 5000 RTP/AVP 96\r\na=rtpmap:96 opus\r\n").Stats()
+	if s.HasICE || s.HasDTLS || len(s.CodecCount) != 0 || s.MediaCount != 1 {
+		t.Errorf("unexpected stats for a plain RTP session: %+v", s)
+	}
+}
@@ -0,0 +1,350 @@
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Scope int
+
+const (
+	ScopeAny Scope = iota
+	ScopeSession
+	ScopeMedia
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeSession:
+		return "session"
+	case ScopeMedia:
+		return "media"
+	default:
+		return "any"
+	}
+}
+
+// Rule names reported in Violation. They are part of the API and are not
+// expected to change.
+const (
+	RuleVersion     = "version"
+	RuleOrigin      = "origin"
+	RuleSessionName = "session-name"
+	RuleTiming      = "timing"
+	RuleMediaConn   = "media-conn"
+	RuleMediaFormat = "media-format"
+	RuleAttrScope   = "attribute-scope"
+	RuleBundleAddr  = "bundle-address-family"
+	RuleDirection   = "direction-ssrc"
+	RuleDynamicMap  = "dynamic-payload-rtpmap"
+	RuleICE         = "ice"
+	RuleOrphanMap   = "orphan-payload-attribute"
+	RuleBundleMedia = "bundle-rejected-media"
+)
+
+type Violation struct {
+	Rule    string
+	Scope   Scope
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s(%s): %s", v.Rule, v.Scope, v.Message)
+}
+
+// Validate checks f against the rules of RFC 4566 and returns an error
+// wrapping ErrInvalid that summarizes all the violations found.
+func (f File) Validate() error {
+	return violationsError(f.ValidationReport())
+}
+
+// ValidateDirectionConsistency only checks that the medias that can not send
+// (recvonly or inactive) do not announce any source.
+func (f File) ValidateDirectionConsistency() error {
+	return violationsError(checkDirection(f))
+}
+
+func violationsError(vs []Violation) error {
+	if len(vs) == 0 {
+		return nil
+	}
+	var msg []string
+	for i := range vs {
+		msg = append(msg, vs[i].String())
+	}
+	return fmt.Errorf("%w: %s", ErrInvalid, strings.Join(msg, "; "))
+}
+
+func (f File) ValidationReport() []Violation {
+	var vs []Violation
+	for _, check := range rules {
+		vs = append(vs, check(f)...)
+	}
+	return vs
+}
+
+var rules = []func(File) []Violation{
+	checkVersion,
+	checkOrigin,
+	checkSessionName,
+	checkTiming,
+	checkMediaConn,
+	checkMediaFormat,
+	checkAttributeScope,
+	checkBundleFamilies,
+	checkBundleRejected,
+	checkDirection,
+	checkDynamicPayloads,
+	checkOrphanPayloads,
+}
+
+func checkVersion(f File) []Violation {
+	if f.Version == 0 {
+		return nil
+	}
+	return []Violation{
+		{Rule: RuleVersion, Scope: ScopeSession, Message: fmt.Sprintf("unsupported version %d", f.Version)},
+	}
+}
+
+func checkOrigin(f File) []Violation {
+	if !f.Session.ConnInfo.IsZero() {
+		return nil
+	}
+	return []Violation{
+		{Rule: RuleOrigin, Scope: ScopeSession, Message: "missing origin address"},
+	}
+}
+
+func checkSessionName(f File) []Violation {
+	if f.Session.Name != "" {
+		return nil
+	}
+	return []Violation{
+		{Rule: RuleSessionName, Scope: ScopeSession, Message: "empty session name"},
+	}
+}
+
+func checkTiming(f File) []Violation {
+	if len(f.Intervals) > 0 {
+		return nil
+	}
+	return []Violation{
+		{Rule: RuleTiming, Scope: ScopeSession, Message: "no time description"},
+	}
+}
+
+func checkMediaConn(f File) []Violation {
+	if !f.ConnInfo.IsZero() {
+		return nil
+	}
+	var vs []Violation
+	for i, m := range f.Medias {
+		if !m.ConnInfo.IsZero() {
+			continue
+		}
+		vs = append(vs, Violation{
+			Rule:    RuleMediaConn,
+			Scope:   ScopeMedia,
+			Message: fmt.Sprintf("media #%d (%s): no connection information", i, m.Media),
+		})
+	}
+	return vs
+}
+
+func checkMediaFormat(f File) []Violation {
+	var vs []Violation
+	for i, m := range f.Medias {
+		if m.Proto != "" && len(m.Attrs) > 0 {
+			continue
+		}
+		vs = append(vs, Violation{
+			Rule:    RuleMediaFormat,
+			Scope:   ScopeMedia,
+			Message: fmt.Sprintf("media #%d (%s): missing proto or format list", i, m.Media),
+		})
+	}
+	return vs
+}
+
+func checkAttributeScope(f File) []Violation {
+	var vs []Violation
+	for _, a := range f.Attributes {
+		if AttributeScope(a.Name) != ScopeMedia {
+			continue
+		}
+		vs = append(vs, Violation{
+			Rule:    RuleAttrScope,
+			Scope:   ScopeSession,
+			Message: fmt.Sprintf("%s: media attribute at session level", a.Name),
+		})
+	}
+	for i, m := range f.Medias {
+		for _, a := range m.Attributes {
+			if AttributeScope(a.Name) != ScopeSession {
+				continue
+			}
+			vs = append(vs, Violation{
+				Rule:    RuleAttrScope,
+				Scope:   ScopeMedia,
+				Message: fmt.Sprintf("media #%d (%s): %s: session attribute at media level", i, m.Media, a.Name),
+			})
+		}
+	}
+	return vs
+}
+
+func checkBundleFamilies(f File) []Violation {
+	gs, err := f.GroupsBy(GroupBundle)
+	if err != nil {
+		return nil
+	}
+	var vs []Violation
+	for _, g := range gs {
+		set := make(map[string]bool)
+		for _, mid := range g.MIDs {
+			i := f.MediaByMID(mid)
+			if i < 0 {
+				continue
+			}
+			conn := f.Medias[i].ConnInfo
+			if conn.IsZero() {
+				conn = f.ConnInfo
+			}
+			if !conn.IsZero() {
+				set[conn.AddrType] = true
+			}
+		}
+		if len(set) <= 1 {
+			continue
+		}
+		vs = append(vs, Violation{
+			Rule:    RuleBundleAddr,
+			Scope:   ScopeSession,
+			Message: fmt.Sprintf("group %s: mixed address families", g),
+		})
+	}
+	return vs
+}
+
+// checkBundleRejected reports the rejected medias still referenced by a BUNDLE
+// group: their mid has to be removed from the group (RFC 8843). A bundle-only
+// media also has its port set to zero and it is not reported.
+func checkBundleRejected(f File) []Violation {
+	gs, err := f.GroupsBy(GroupBundle)
+	if err != nil {
+		return nil
+	}
+	var vs []Violation
+	for _, g := range gs {
+		for _, mid := range g.MIDs {
+			i := f.MediaByMID(mid)
+			if i < 0 || f.Medias[i].Port != 0 || f.Medias[i].BundleOnly() {
+				continue
+			}
+			vs = append(vs, Violation{
+				Rule:    RuleBundleMedia,
+				Scope:   ScopeSession,
+				Message: fmt.Sprintf("group %s: media %s is rejected", g, mid),
+			})
+		}
+	}
+	return vs
+}
+
+func checkDirection(f File) []Violation {
+	var vs []Violation
+	for i, m := range f.Medias {
+		dir := f.EffectiveDirection(m)
+		if dir.CanSend() {
+			continue
+		}
+		for _, a := range m.Attributes {
+			if a.Name != "ssrc" && a.Name != "ssrc-group" {
+				continue
+			}
+			vs = append(vs, Violation{
+				Rule:    RuleDirection,
+				Scope:   ScopeMedia,
+				Message: fmt.Sprintf("media #%d (%s): %s media with %s attribute", i, m.Media, dir, a.Name),
+			})
+			break
+		}
+	}
+	return vs
+}
+
+// checkDynamicPayloads reports the dynamic payloads (96-127) of RTP medias
+// without rtpmap. Rejected medias only keep their formats to be valid m= lines
+// (eg: in an answer, see RejectAll) and they are not checked. A bundle-only
+// media also has a zero port but it is not rejected.
+func checkDynamicPayloads(f File) []Violation {
+	var vs []Violation
+	for i, m := range f.Medias {
+		if !m.UsesRTP() || (m.Port == 0 && !m.BundleOnly()) {
+			continue
+		}
+		mapped := make(map[string]bool)
+		for _, a := range m.Attributes {
+			if a.Name == "rtpmap" {
+				mapped[payloadOf(a.Value)] = true
+			}
+		}
+		for _, p := range m.Attrs {
+			n, err := strconv.ParseUint(p, 10, 8)
+			if err != nil || n < 96 || n > 127 || mapped[p] {
+				continue
+			}
+			vs = append(vs, Violation{
+				Rule:    RuleDynamicMap,
+				Scope:   ScopeMedia,
+				Message: fmt.Sprintf("media #%d (%s): dynamic payload %d without rtpmap", i, m.Media, n),
+			})
+		}
+	}
+	return vs
+}
+
+// checkOrphanPayloads reports the rtpmap and fmtp attributes of RTP medias
+// referencing a payload missing from the format list, and the fmtp attributes
+// of a payload without rtpmap. Static payloads do not need a rtpmap.
+func checkOrphanPayloads(f File) []Violation {
+	var vs []Violation
+	for i, m := range f.Medias {
+		if !m.UsesRTP() {
+			continue
+		}
+		mapped := make(map[string]bool)
+		for _, a := range m.Attributes {
+			if a.Name == "rtpmap" {
+				mapped[payloadOf(a.Value)] = true
+			}
+		}
+		report := func(format string, args ...any) {
+			vs = append(vs, Violation{
+				Rule:    RuleOrphanMap,
+				Scope:   ScopeMedia,
+				Message: fmt.Sprintf("media #%d (%s): ", i, m.Media) + fmt.Sprintf(format, args...),
+			})
+		}
+		for _, a := range m.Attributes {
+			if a.Name != "rtpmap" && a.Name != "fmtp" {
+				continue
+			}
+			p := payloadOf(a.Value)
+			if !hasFormat(m.Attrs, p) {
+				report("%s for payload %s not in format list", a.Name, p)
+				continue
+			}
+			if a.Name == "fmtp" && !mapped[p] {
+				n, err := strconv.ParseUint(p, 10, 8)
+				if _, ok := statics[uint8(n)]; err == nil && ok {
+					continue
+				}
+				report("fmtp for payload %s without rtpmap", p)
+			}
+		}
+	}
+	return vs
+}
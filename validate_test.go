@@ -0,0 +1,267 @@
+package sdp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateAttributeScope(t *testing.T) {
+	data := []struct {
+		Name  string
+		Scope Scope
+	}{
+		{Name: "rtpmap", Scope: ScopeMedia},
+		{Name: "group", Scope: ScopeSession},
+		{Name: "x-unknown", Scope: ScopeAny},
+	}
+	for _, d := range data {
+		if s := AttributeScope(d.Name); s != d.Scope {
+			t.Errorf("%s: want scope %s, got %s", d.Name, d.Scope, s)
+		}
+	}
+
+	f := MustParse(offerHead +
+		"a=rtpmap:0 PCMU/8000\r\n" +
+		"m=audio 5000 RTP/AVP 0\r\n" +
+		"a=group:BUNDLE a\r\n")
+	vs := violationsOf(f, RuleAttrScope)
+	if len(vs) != 2 {
+		t.Fatalf("want 2 violations, got %v", vs)
+	}
+	if vs[0].Scope != ScopeSession || vs[0].Message != "rtpmap: media attribute at session level" {
+		t.Errorf("misplaced rtpmap: got %s", vs[0])
+	}
+	if vs[1].Scope != ScopeMedia {
+		t.Errorf("misplaced group: got %s", vs[1])
+	}
+	if err := f.Validate(); err == nil {
+		t.Errorf("expected error")
+	}
+
+	f = MustParse(offerHead + "m=audio 5000 RTP/AVP 0\r\na=rtpmap:0 PCMU/8000\r\n")
+	if vs := violationsOf(f, RuleAttrScope); len(vs) != 0 {
+		t.Errorf("unexpected violations: %v", vs)
+	}
+}
+
+// violationsOf returns the violations of the given rule found in f.
+func violationsOf(f File, rule string) []Violation {
+	var arr []Violation
+	for _, v := range f.ValidationReport() {
+		if v.Rule == rule {
+			arr = append(arr, v)
+		}
+	}
+	return arr
+}
+
+func TestValidateBundleFamilies(t *testing.T) {
+	const medias = "m=audio 5000 RTP/AVP 0\r\na=mid:a\r\n" +
+		"m=video 5002 RTP/AVP 96\r\nc=IN IP6 2001:db8::1\r\n" +
+		"a=rtpmap:96 VP8/90000\r\na=mid:v\r\n" +
+		"m=audio 5004 RTP/AVP 0\r\na=mid:b\r\n"
+	data := []struct {
+		Group      string
+		Violations int
+	}{
+		{Group: "a=group:BUNDLE a v\r\n", Violations: 1},
+		{Group: "a=group:BUNDLE a b\r\n"},
+		{Group: "a=group:BUNDLE v\r\na=group:LS a v\r\n"},
+	}
+	for _, d := range data {
+		f := MustParse(offerHead + d.Group + medias)
+		fs := f.AddressFamilies()
+		if len(fs) != 2 || !fs[AddrType4] || !fs[AddrType6] {
+			t.Errorf("%q: unexpected families %v", d.Group, fs)
+		}
+		if vs := violationsOf(f, RuleBundleAddr); len(vs) != d.Violations {
+			t.Errorf("%q: want %d violations, got %v", d.Group, d.Violations, vs)
+		}
+	}
+}
+
+func TestValidateDirectionConsistency(t *testing.T) {
+	const ssrc = "a=ssrc:1234 cname:user@example.com\r\n"
+	data := []struct {
+		Name    string
+		Input   string
+		Invalid bool
+	}{
+		{
+			Name:  "sendrecv",
+			Input: "m=audio 5000 RTP/AVP 0\r\na=sendrecv\r\n" + ssrc,
+		},
+		{
+			Name:  "sendonly",
+			Input: "m=audio 5000 RTP/AVP 0\r\na=sendonly\r\n" + ssrc,
+		},
+		{
+			Name:  "recvonly without ssrc",
+			Input: "m=audio 5000 RTP/AVP 0\r\na=recvonly\r\n",
+		},
+		{
+			Name:    "recvonly",
+			Input:   "m=audio 5000 RTP/AVP 0\r\na=recvonly\r\n" + ssrc,
+			Invalid: true,
+		},
+		{
+			Name:    "recvonly with group",
+			Input:   "m=video 5002 RTP/AVP 96\r\na=recvonly\r\na=rtpmap:96 VP8/90000\r\na=ssrc-group:FID 1234 5678\r\n",
+			Invalid: true,
+		},
+		{
+			Name:    "session inactive",
+			Input:   "a=inactive\r\nm=audio 5000 RTP/AVP 0\r\n" + ssrc,
+			Invalid: true,
+		},
+	}
+	for _, d := range data {
+		f := MustParse(offerHead + d.Input)
+		err := f.ValidateDirectionConsistency()
+		if !d.Invalid {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", d.Name, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected %s, got %v", d.Name, ErrInvalid, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "media #0") {
+			t.Errorf("%s: media not reported: %s", d.Name, err)
+		}
+		if vs := violationsOf(f, RuleDirection); len(vs) != 1 {
+			t.Errorf("%s: want 1 violation, got %v", d.Name, vs)
+		}
+	}
+}
+
+func TestValidateDynamicPayloads(t *testing.T) {
+	data := []struct {
+		Name     string
+		Media    string
+		Messages []string
+	}{
+		{
+			Name:  "mapped",
+			Media: "m=video 5000 RTP/AVP 96 97\r\na=rtpmap:96 VP8/90000\r\na=rtpmap:97 rtx/90000\r\n",
+		},
+		{
+			Name:  "static",
+			Media: "m=audio 5000 RTP/AVP 0 8\r\n",
+		},
+		{
+			Name:     "missing rtpmap",
+			Media:    "m=video 5000 RTP/AVP 96 97 0\r\na=rtpmap:96 VP8/90000\r\n",
+			Messages: []string{"media #0 (video): dynamic payload 97 without rtpmap"},
+		},
+		{
+			Name:     "bundle-only",
+			Media:    "m=video 0 RTP/AVP 127\r\na=bundle-only\r\n",
+			Messages: []string{"media #0 (video): dynamic payload 127 without rtpmap"},
+		},
+		{
+			Name:  "rejected",
+			Media: "m=video 0 RTP/AVP 96\r\n",
+		},
+		{
+			Name:  "not rtp",
+			Media: "m=application 5000 UDP/DTLS/SCTP 100\r\n",
+		},
+	}
+	for _, d := range data {
+		vs := violationsOf(MustParse(offerHead+d.Media), RuleDynamicMap)
+		if len(vs) != len(d.Messages) {
+			t.Errorf("%s: want %d violations, got %v", d.Name, len(d.Messages), vs)
+			continue
+		}
+		for i := range vs {
+			if vs[i].Scope != ScopeMedia || vs[i].Message != d.Messages[i] {
+				t.Errorf("%s: want %q, got %s", d.Name, d.Messages[i], vs[i])
+			}
+		}
+	}
+	err := MustParse(offerHead + "m=video 5000 RTP/AVP 96\r\n").Validate()
+	if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), RuleDynamicMap) {
+		t.Errorf("expected %s, got %v", RuleDynamicMap, err)
+	}
+}
+
+func TestValidateOrphanPayloads(t *testing.T) {
+	data := []struct {
+		Name     string
+		Media    string
+		Messages []string
+	}{
+		{
+			Name:  "mapped",
+			Media: "m=video 5000 RTP/AVP 96\r\na=rtpmap:96 H264/90000\r\na=fmtp:96 packetization-mode=1\r\n",
+		},
+		{
+			Name:  "static",
+			Media: "m=audio 5000 RTP/AVP 18\r\na=fmtp:18 annexb=no\r\n",
+		},
+		{
+			Name:     "fmtp without rtpmap",
+			Media:    "m=video 5000 RTP/AVP 96 97\r\na=rtpmap:96 H264/90000\r\na=fmtp:97 apt=96\r\n",
+			Messages: []string{"media #0 (video): fmtp for payload 97 without rtpmap"},
+		},
+		{
+			Name:     "fmtp not in format list",
+			Media:    "m=video 5000 RTP/AVP 96\r\na=rtpmap:96 H264/90000\r\na=fmtp:98 apt=96\r\n",
+			Messages: []string{"media #0 (video): fmtp for payload 98 not in format list"},
+		},
+		{
+			Name:     "rtpmap not in format list",
+			Media:    "m=audio 5000 RTP/AVP 0\r\na=rtpmap:8 PCMA/8000\r\n",
+			Messages: []string{"media #0 (audio): rtpmap for payload 8 not in format list"},
+		},
+	}
+	for _, d := range data {
+		vs := violationsOf(MustParse(offerHead+d.Media), RuleOrphanMap)
+		if len(vs) != len(d.Messages) {
+			t.Errorf("%s: want %d violations, got %v", d.Name, len(d.Messages), vs)
+			continue
+		}
+		for i := range vs {
+			if vs[i].Scope != ScopeMedia || vs[i].Message != d.Messages[i] {
+				t.Errorf("%s: want %q, got %s", d.Name, d.Messages[i], vs[i])
+			}
+		}
+	}
+}
+
+func TestValidateBundleRejected(t *testing.T) {
+	const medias = "m=audio 9 UDP/TLS/RTP/SAVPF 111\r\na=mid:a\r\na=rtpmap:111 opus/48000/2\r\n" +
+		"m=video 0 UDP/TLS/RTP/SAVPF 96\r\na=mid:v\r\na=rtpmap:96 VP8/90000\r\n" +
+		"m=video 0 UDP/TLS/RTP/SAVPF 96\r\na=mid:w\r\na=bundle-only\r\na=rtpmap:96 VP8/90000\r\n"
+	data := []struct {
+		Group    string
+		Messages []string
+	}{
+		{Group: "a=group:BUNDLE a w\r\n"},
+		{Group: "a=group:LS a v\r\n"},
+		{
+			Group:    "a=group:BUNDLE a v w\r\n",
+			Messages: []string{"group BUNDLE a v w: media v is rejected"},
+		},
+		{
+			Group:    "a=group:BUNDLE v\r\na=group:BUNDLE a w\r\n",
+			Messages: []string{"group BUNDLE v: media v is rejected"},
+		},
+	}
+	for _, d := range data {
+		vs := violationsOf(MustParse(offerHead+d.Group+medias), RuleBundleMedia)
+		if len(vs) != len(d.Messages) {
+			t.Errorf("%q: want %d violations, got %v", d.Group, len(d.Messages), vs)
+			continue
+		}
+		for i := range vs {
+			if vs[i].Scope != ScopeSession || vs[i].Message != d.Messages[i] {
+				t.Errorf("%q: want %q, got %s", d.Group, d.Messages[i], vs[i])
+			}
+		}
+	}
+}
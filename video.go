@@ -0,0 +1,345 @@
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ImageRange is a set of image dimensions. It is either a list of discrete
+// values or a range between Min and Max with an optional Step.
+type ImageRange struct {
+	Values []int
+	Min    int
+	Max    int
+	Step   int
+}
+
+func (r ImageRange) Contains(n int) bool {
+	if len(r.Values) > 0 {
+		for _, v := range r.Values {
+			if v == n {
+				return true
+			}
+		}
+		return false
+	}
+	if n < r.Min || n > r.Max {
+		return false
+	}
+	return r.Step <= 1 || (n-r.Min)%r.Step == 0
+}
+
+func (r ImageRange) String() string {
+	if len(r.Values) == 1 {
+		return strconv.Itoa(r.Values[0])
+	}
+	var parts []string
+	if len(r.Values) > 0 {
+		for _, v := range r.Values {
+			parts = append(parts, strconv.Itoa(v))
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	}
+	parts = append(parts, strconv.Itoa(r.Min))
+	if r.Step > 0 {
+		parts = append(parts, strconv.Itoa(r.Step))
+	}
+	parts = append(parts, strconv.Itoa(r.Max))
+	return "[" + strings.Join(parts, ":") + "]"
+}
+
+type ImageSet struct {
+	X      ImageRange
+	Y      ImageRange
+	Params []Attribute
+}
+
+func (s ImageSet) String() string {
+	var str strings.Builder
+	str.WriteString("[x=")
+	str.WriteString(s.X.String())
+	str.WriteString(",y=")
+	str.WriteString(s.Y.String())
+	for _, p := range s.Params {
+		str.WriteByte(',')
+		str.WriteString(p.Name)
+		str.WriteByte('=')
+		str.WriteString(p.Value)
+	}
+	str.WriteByte(']')
+	return str.String()
+}
+
+// ImageAttr is the value of an imageattr attribute (RFC 6236). SendAny and
+// RecvAny are set when the wildcard is used in place of a list of sets.
+type ImageAttr struct {
+	Payload string
+	Send    []ImageSet
+	SendAny bool
+	Recv    []ImageSet
+	RecvAny bool
+}
+
+func (a ImageAttr) String() string {
+	var str strings.Builder
+	str.WriteString(a.Payload)
+	writeSets := func(dir string, wild bool, sets []ImageSet) {
+		if !wild && len(sets) == 0 {
+			return
+		}
+		str.WriteByte(' ')
+		str.WriteString(dir)
+		if wild {
+			str.WriteString(" *")
+			return
+		}
+		for _, s := range sets {
+			str.WriteByte(' ')
+			str.WriteString(s.String())
+		}
+	}
+	writeSets("send", a.SendAny, a.Send)
+	writeSets("recv", a.RecvAny, a.Recv)
+	return str.String()
+}
+
+func (m MediaInfo) ImageAttrs() ([]ImageAttr, error) {
+	var arr []ImageAttr
+	for _, a := range m.Attributes {
+		if a.Name != "imageattr" {
+			continue
+		}
+		i, err := parseImageAttr(a.Value)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, i)
+	}
+	return arr, nil
+}
+
+// imageattr:<pt> 1*2(send|recv <set>...|*)
+func parseImageAttr(str string) (ImageAttr, error) {
+	var (
+		attr  ImageAttr
+		parts = strings.Fields(str)
+	)
+	if len(parts) < 3 {
+		return attr, fmt.Errorf("%w: imageattr (%s)", ErrSyntax, str)
+	}
+	attr.Payload = parts[0]
+	if attr.Payload != "*" {
+		if _, err := strconv.ParseUint(attr.Payload, 10, 8); err != nil {
+			return attr, fmt.Errorf("%w - imageattr payload: %s", ErrSyntax, err)
+		}
+	}
+	var (
+		sets *[]ImageSet
+		wild *bool
+		seen = make(map[string]bool)
+	)
+	for _, p := range parts[1:] {
+		switch p {
+		case "send", "recv":
+			if seen[p] {
+				return attr, fmt.Errorf("%w: imageattr: duplicate %s", ErrSyntax, p)
+			}
+			seen[p] = true
+			if p == "send" {
+				sets, wild = &attr.Send, &attr.SendAny
+			} else {
+				sets, wild = &attr.Recv, &attr.RecvAny
+			}
+		case "*":
+			if sets == nil || *wild || len(*sets) > 0 {
+				return attr, fmt.Errorf("%w: imageattr: unexpected wildcard", ErrSyntax)
+			}
+			*wild = true
+		default:
+			if sets == nil || *wild {
+				return attr, fmt.Errorf("%w: imageattr: unexpected set %s", ErrSyntax, p)
+			}
+			s, err := parseImageSet(p)
+			if err != nil {
+				return attr, err
+			}
+			*sets = append(*sets, s)
+		}
+	}
+	if (seen["send"] && !attr.SendAny && len(attr.Send) == 0) || (seen["recv"] && !attr.RecvAny && len(attr.Recv) == 0) {
+		return attr, fmt.Errorf("%w: imageattr: empty set list (%s)", ErrSyntax, str)
+	}
+	return attr, nil
+}
+
+// [x=<xyrange>,y=<xyrange>*(,<key>=<value>)]
+func parseImageSet(str string) (ImageSet, error) {
+	var set ImageSet
+	if !strings.HasPrefix(str, "[") || !strings.HasSuffix(str, "]") {
+		return set, fmt.Errorf("%w: imageattr set (%s)", ErrSyntax, str)
+	}
+	parts := splitTopLevel(str[1:len(str)-1], ',')
+	if len(parts) < 2 || !strings.HasPrefix(parts[0], "x=") || !strings.HasPrefix(parts[1], "y=") {
+		return set, fmt.Errorf("%w: imageattr set (%s)", ErrSyntax, str)
+	}
+	var err error
+	if set.X, err = parseImageRange(parts[0][2:]); err != nil {
+		return set, err
+	}
+	if set.Y, err = parseImageRange(parts[1][2:]); err != nil {
+		return set, err
+	}
+	for _, p := range parts[2:] {
+		x := strings.Index(p, "=")
+		if x <= 0 {
+			return set, fmt.Errorf("%w: imageattr parameter (%s)", ErrSyntax, p)
+		}
+		set.Params = append(set.Params, Attribute{Name: p[:x], Value: p[x+1:]})
+	}
+	return set, nil
+}
+
+func parseImageRange(str string) (ImageRange, error) {
+	var (
+		r   ImageRange
+		err error
+	)
+	if !strings.HasPrefix(str, "[") {
+		n, err := strconv.Atoi(str)
+		if err != nil {
+			return r, fmt.Errorf("%w - imageattr range: %s", ErrSyntax, err)
+		}
+		r.Values = append(r.Values, n)
+		return r, nil
+	}
+	if !strings.HasSuffix(str, "]") {
+		return r, fmt.Errorf("%w: imageattr range (%s)", ErrSyntax, str)
+	}
+	str = str[1 : len(str)-1]
+	if strings.Contains(str, ":") {
+		parts := strings.Split(str, ":")
+		if len(parts) > 3 {
+			return r, fmt.Errorf("%w: imageattr range (%s)", ErrSyntax, str)
+		}
+		if r.Min, err = strconv.Atoi(parts[0]); err != nil {
+			return r, fmt.Errorf("%w - imageattr range: %s", ErrSyntax, err)
+		}
+		if r.Max, err = strconv.Atoi(parts[len(parts)-1]); err != nil {
+			return r, fmt.Errorf("%w - imageattr range: %s", ErrSyntax, err)
+		}
+		if len(parts) == 3 {
+			if r.Step, err = strconv.Atoi(parts[1]); err != nil {
+				return r, fmt.Errorf("%w - imageattr range: %s", ErrSyntax, err)
+			}
+		}
+		if r.Min > r.Max {
+			return r, fmt.Errorf("%w: imageattr range (%s)", ErrInvalid, str)
+		}
+		return r, nil
+	}
+	for _, p := range strings.Split(str, ",") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return r, fmt.Errorf("%w - imageattr range: %s", ErrSyntax, err)
+		}
+		r.Values = append(r.Values, n)
+	}
+	return r, nil
+}
+
+// splitTopLevel splits str on sep ignoring the separators found between
+// square brackets.
+func splitTopLevel(str string, sep byte) []string {
+	var (
+		parts []string
+		depth int
+		last  int
+	)
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, str[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, str[last:])
+}
+
+// Video2110Params are the format parameters of an uncompressed video stream
+// of SMPTE ST 2110-20 (eg: a=fmtp:96 sampling=YCbCr-4:2:2; width=1920;
+// height=1080; exactframerate=30000/1001; depth=10; colorimetry=BT709).
+type Video2110Params struct {
+	Sampling    string
+	Width       int
+	Height      int
+	Depth       int
+	RateNum     int
+	RateDen     int
+	Colorimetry string
+}
+
+// FrameRate returns the exact frame rate as a floating point number.
+func (v Video2110Params) FrameRate() float64 {
+	if v.RateDen == 0 {
+		return 0
+	}
+	return float64(v.RateNum) / float64(v.RateDen)
+}
+
+// Video2110 returns the ST 2110-20 view of p. An error is returned if one of
+// the parameters is missing or if a numeric parameter is not a positive
+// integer.
+func (p FormatParams) Video2110() (Video2110Params, error) {
+	var (
+		v   Video2110Params
+		err error
+	)
+	get := func(name string) string {
+		if err != nil {
+			return ""
+		}
+		str, ok := p.Get(name)
+		if !ok || str == "" {
+			err = fmt.Errorf("%w: fmtp %d: missing %s", ErrInvalid, p.Payload, name)
+		}
+		return str
+	}
+	atoi := func(name string) int {
+		str := get(name)
+		if err != nil {
+			return 0
+		}
+		n, e := strconv.Atoi(str)
+		if e != nil || n <= 0 {
+			err = fmt.Errorf("%w: fmtp %d: invalid %s (%s)", ErrInvalid, p.Payload, name, str)
+		}
+		return n
+	}
+	v.Sampling = get("sampling")
+	v.Width = atoi("width")
+	v.Height = atoi("height")
+	v.Depth = atoi("depth")
+	v.Colorimetry = get("colorimetry")
+	rate := get("exactframerate")
+	if err != nil {
+		return v, err
+	}
+	num, den, ok := strings.Cut(rate, "/")
+	if !ok {
+		den = "1"
+	}
+	var err1, err2 error
+	v.RateNum, err1 = strconv.Atoi(num)
+	v.RateDen, err2 = strconv.Atoi(den)
+	if err1 != nil || err2 != nil || v.RateNum <= 0 || v.RateDen <= 0 {
+		return v, fmt.Errorf("%w: fmtp %d: invalid exactframerate (%s)", ErrInvalid, p.Payload, rate)
+	}
+	return v, nil
+}
@@ -0,0 +1,58 @@
+package sdp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVideo2110(t *testing.T) {
+	const media = "m=video 50000 RTP/AVP 96\r\n" +
+		"c=IN IP4 239.100.9.10/32\r\n" +
+		"a=source-filter: incl IN IP4 239.100.9.10 192.168.100.2\r\n" +
+		"a=rtpmap:96 raw/90000\r\n"
+	f := MustParse(offerHead + media +
+		"a=fmtp:96 sampling=YCbCr-4:2:2; width=1920; height=1080; exactframerate=30000/1001; depth=10; TCS=SDR; colorimetry=BT709; PM=2110GPM; SSN=ST2110-20:2017; TP=2110TPN;\r\n" +
+		"a=mediaclk:direct=0\r\n" +
+		"a=ts-refclk:ptp=IEEE1588-2008:39-A7-94-FF-FE-07-CB-D0:37\r\n")
+	p, err := f.Medias[0].FormatParams(96)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	v, err := p.Video2110()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := Video2110Params{
+		Sampling:    "YCbCr-4:2:2",
+		Width:       1920,
+		Height:      1080,
+		Depth:       10,
+		RateNum:     30000,
+		RateDen:     1001,
+		Colorimetry: "BT709",
+	}
+	if v != want {
+		t.Errorf("want %+v, got %+v", want, v)
+	}
+	if r := v.FrameRate(); r < 29.97 || r > 29.98 {
+		t.Errorf("unexpected frame rate %f", r)
+	}
+
+	data := []string{
+		"sampling=YCbCr-4:2:2; width=1920; exactframerate=25; depth=10; colorimetry=BT709",
+		"sampling=YCbCr-4:2:2; width=1920; height=-1080; exactframerate=25; depth=10; colorimetry=BT709",
+		"sampling=YCbCr-4:2:2; width=1920; height=1080; exactframerate=25/0; depth=10; colorimetry=BT709",
+		"sampling=YCbCr-4:2:2; width=wide; height=1080; exactframerate=25; depth=10; colorimetry=BT709",
+	}
+	for _, str := range data {
+		f := MustParse(offerHead + media + "a=fmtp:96 " + str + "\r\n")
+		p, err := f.Medias[0].FormatParams(96)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", str, err)
+			continue
+		}
+		if _, err := p.Video2110(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected %s, got %v", str, ErrInvalid, err)
+		}
+	}
+}